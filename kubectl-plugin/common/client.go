@@ -79,6 +79,20 @@ func (c *ClientFactory) GetClient() (client dynamic.Interface, clientSet *kubern
 	return
 }
 
+// GetDynamicClient returns the dynamic client, the error of loading the kubeconfig is returned
+func (c *ClientFactory) GetDynamicClient() (client dynamic.Interface, err error) {
+	KubernetesConfigFlags := genericclioptions.NewConfigFlags(false)
+	if c.context != "" {
+		KubernetesConfigFlags.Context = &c.context
+	}
+
+	var config *rest.Config
+	if config, err = KubernetesConfigFlags.ToRESTConfig(); err == nil {
+		client, err = dynamic.NewForConfig(config)
+	}
+	return
+}
+
 // SetContext sets the k8s context
 func (c *ClientFactory) SetContext(ctx string) {
 	c.context = ctx
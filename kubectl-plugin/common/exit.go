@@ -0,0 +1,12 @@
+package common
+
+// ExitCodeError is an error which carries the exit code of the process
+type ExitCodeError struct {
+	Code    int
+	Message string
+}
+
+// Error returns the message of the error
+func (e *ExitCodeError) Error() string {
+	return e.Message
+}
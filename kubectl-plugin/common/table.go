@@ -0,0 +1,32 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// PrintTable prints the rows as a table which is aligned by tabs, the headers are omitted if they are empty
+func PrintTable(w io.Writer, headers []string, rows [][]string) (err error) {
+	writer := printers.GetNewTabWriter(w)
+	if len(headers) > 0 {
+		rows = append([][]string{headers}, rows...)
+	}
+	for _, row := range rows {
+		if _, err = fmt.Fprintln(writer, strings.Join(row, "\t")); err != nil {
+			return
+		}
+	}
+	err = writer.Flush()
+	return
+}
+
+// EmptyAsNone returns <none> if the text is empty, it's the way kubectl prints the empty values
+func EmptyAsNone(text string) string {
+	if text == "" {
+		return "<none>"
+	}
+	return text
+}
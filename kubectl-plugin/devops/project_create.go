@@ -0,0 +1,78 @@
+package devops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newProjectCreateCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &projectCreateOption{
+		PipelineCreateOption: option.PipelineCreateOption{
+			Client: client,
+		},
+	}
+	cmd = &cobra.Command{
+		Use:   "create",
+		Short: "Create a DevOps project",
+		Long: `Create a DevOps project
+The workspace will be created if it does not exist. The namespace of the DevOps project is generated by KubeSphere.`,
+		Example: `ks devops project create my-project --workspace my-ws
+ks devops project create my-project --workspace my-ws --description "the demo project" --creator tester`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.Workspace, "workspace", "", "",
+		"The workspace of the DevOps project")
+	flags.StringVarP(&opt.ProjectDescription, "description", "", "",
+		"The description of the DevOps project")
+	flags.StringVarP(&opt.Creator, "creator", "", "admin",
+		"The creator of the DevOps project")
+	flags.BoolVarP(&opt.SkipCheck, "skip-check", "", false, "Skip the workspace check")
+	_ = cmd.MarkFlagRequired("workspace")
+	return
+}
+
+type projectCreateOption struct {
+	option.PipelineCreateOption
+}
+
+func (o *projectCreateOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.Client == nil {
+		o.Client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	o.Project = args[0]
+	return
+}
+
+func (o *projectCreateOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	if existing, getErr := option.GetDevOpsProject(context.TODO(), o.Client, o.Project); getErr == nil {
+		err = fmt.Errorf("DevOps project '%s' already exists, the namespace is %s", o.Project, existing.GetName())
+		return
+	}
+
+	var wsID string
+	if !o.SkipCheck {
+		var ws *unstructured.Unstructured
+		if ws, err = o.CheckWorkspace(); err != nil {
+			err = fmt.Errorf("cannot find workspace %s, error: %v", o.Workspace, err)
+			return
+		}
+		wsID = string(ws.GetUID())
+	}
+
+	var project *unstructured.Unstructured
+	if project, err = o.CheckDevOpsProject(wsID); err != nil {
+		return
+	}
+	cmd.Printf("DevOps project %s created, the namespace is %s\n", o.Project, project.GetName())
+	return
+}
@@ -0,0 +1,160 @@
+package devops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+func newProjectDeleteCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &projectDeleteOption{
+		client:   client,
+		interval: time.Second,
+	}
+	cmd = &cobra.Command{
+		Use:     "delete",
+		Aliases: []string{"del", "rm"},
+		Short:   "Delete a DevOps project",
+		Long: `Delete a DevOps project
+The DevOps project which has Pipelines is not deleted unless --force is given.
+The finalizers of the DevOpsProject are handled by the KubeSphere DevOps controller, which removes the resources in Jenkins.
+If the controller does not remove them in time, they could be removed by --remove-finalizers,
+the resources in Jenkins might be left in this case.`,
+		Example: `ks devops project delete my-project
+ks devops project delete my-project --force --yes`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opt.force, "force", "", false,
+		"Delete the DevOps project even if it has Pipelines")
+	flags.BoolVarP(&opt.yes, "yes", "y", false,
+		"Delete the DevOps project without the confirmation")
+	flags.DurationVarP(&opt.timeout, "timeout", "", time.Minute,
+		"The timeout of waiting for the DevOps project to be removed, zero means do not wait")
+	flags.BoolVarP(&opt.removeFinalizers, "remove-finalizers", "", false,
+		"Remove the finalizers if the DevOps project is not removed after the timeout")
+	return
+}
+
+type projectDeleteOption struct {
+	force            bool
+	yes              bool
+	timeout          time.Duration
+	removeFinalizers bool
+
+	// inner fields
+	client   dynamic.Interface
+	interval time.Duration
+}
+
+func (o *projectDeleteOption) preRunE(cmd *cobra.Command, _ []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	return
+}
+
+func (o *projectDeleteOption) runE(cmd *cobra.Command, args []string) (err error) {
+	ctx := context.TODO()
+	var project *unstructured.Unstructured
+	if project, err = option.GetDevOpsProject(ctx, o.client, args[0]); err != nil {
+		return
+	}
+	name := project.GetName()
+
+	var count int
+	if count, err = getPipelineCount(ctx, o.client, name); err != nil {
+		return
+	}
+	if count > 0 && !o.force {
+		err = fmt.Errorf("DevOps project %s has %d Pipelines, please delete them first or use --force", args[0], count)
+		return
+	}
+
+	if !o.yes {
+		var ok bool
+		message := fmt.Sprintf("Delete DevOps project %s (namespace %s) with %d Pipelines?", args[0], name, count)
+		if err = survey.AskOne(&survey.Confirm{Message: message}, &ok); err != nil || !ok {
+			if err == nil {
+				cmd.Println("aborted")
+			}
+			return
+		}
+	}
+
+	// it's being deleted if the deletion timestamp exists, wait for it instead of deleting it again
+	if project.GetDeletionTimestamp() == nil {
+		if err = o.client.Resource(types.GetDevOpsProjectSchema()).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			err = fmt.Errorf("failed to delete DevOps project %s, error: %v", name, err)
+			return
+		}
+	}
+	if o.timeout <= 0 {
+		cmd.Printf("DevOps project %s is being deleted\n", args[0])
+		return
+	}
+
+	var remaining *unstructured.Unstructured
+	if remaining, err = o.waitForRemoved(ctx, name); err != nil {
+		return
+	}
+	if remaining == nil {
+		cmd.Printf("DevOps project %s deleted\n", args[0])
+		return
+	}
+
+	finalizers := remaining.GetFinalizers()
+	if len(finalizers) == 0 {
+		err = fmt.Errorf("DevOps project %s is not removed in %v", args[0], o.timeout)
+		return
+	}
+	if !o.removeFinalizers {
+		err = fmt.Errorf("DevOps project %s is not removed in %v, it's waiting for the finalizers: %s. "+
+			"Please check the KubeSphere DevOps controller, or remove them by --remove-finalizers",
+			args[0], o.timeout, strings.Join(finalizers, ","))
+		return
+	}
+	if _, err = o.client.Resource(types.GetDevOpsProjectSchema()).Patch(ctx, name, k8stypes.MergePatchType,
+		[]byte(`{"metadata":{"finalizers":null}}`), metav1.PatchOptions{}); err != nil {
+		err = fmt.Errorf("failed to remove the finalizers of DevOps project %s, error: %v", name, err)
+		return
+	}
+	cmd.PrintErrf("the finalizers %s of DevOps project %s are removed, the resources in Jenkins might be left\n",
+		strings.Join(finalizers, ","), args[0])
+	cmd.Printf("DevOps project %s deleted\n", args[0])
+	return
+}
+
+// waitForRemoved waits until the DevOpsProject is removed, returns the remaining one if it's timeout
+func (o *projectDeleteOption) waitForRemoved(ctx context.Context, name string) (project *unstructured.Unstructured, err error) {
+	deadline := time.Now().Add(o.timeout)
+	for {
+		if project, err = o.client.Resource(types.GetDevOpsProjectSchema()).Get(ctx, name, metav1.GetOptions{}); err != nil {
+			project = nil
+			if errors.IsNotFound(err) {
+				err = nil
+			}
+			return
+		}
+
+		if time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(o.interval)
+	}
+}
@@ -0,0 +1,150 @@
+package devops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+)
+
+func newProjectDescribeCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &projectDescribeOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:     "describe",
+		Aliases: []string{"desc"},
+		Short:   "Show the details of a DevOps project, including the members and role bindings",
+		Example: `ks devops project describe my-project`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+	return
+}
+
+type projectDescribeOption struct {
+	// inner fields
+	client dynamic.Interface
+}
+
+// projectMember is a user who is bound to a role of the DevOps project
+type projectMember struct {
+	name  string
+	roles []string
+}
+
+func (o *projectDescribeOption) preRunE(cmd *cobra.Command, _ []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	return
+}
+
+func (o *projectDescribeOption) runE(cmd *cobra.Command, args []string) (err error) {
+	ctx := context.TODO()
+	var project *unstructured.Unstructured
+	if project, err = option.GetDevOpsProject(ctx, o.client, args[0]); err != nil {
+		return
+	}
+	ns := project.GetName()
+
+	var count int
+	if count, err = getPipelineCount(ctx, o.client, ns); err != nil {
+		return
+	}
+
+	var bindings *unstructured.UnstructuredList
+	if bindings, err = o.client.Resource(types.GetRoleBindingSchema()).Namespace(ns).List(ctx, metav1.ListOptions{}); err != nil {
+		err = fmt.Errorf("failed to get RoleBinding list in namespace '%s', error: %v", ns, err)
+		return
+	}
+
+	status := "Active"
+	if project.GetDeletionTimestamp() != nil {
+		status = "Terminating"
+	}
+	annotations := project.GetAnnotations()
+	err = common.PrintTable(cmd.OutOrStdout(), nil, [][]string{
+		{"Name:", common.EmptyAsNone(project.GetGenerateName())},
+		{"Namespace:", ns},
+		{"Workspace:", common.EmptyAsNone(project.GetLabels()[option.DevOpsProjectWorkspaceLabelKey])},
+		{"Creator:", common.EmptyAsNone(annotations[option.DevOpsProjectCreatorAnnotationKey])},
+		{"Description:", common.EmptyAsNone(annotations[option.DevOpsProjectDescriptionAnnotationKey])},
+		{"Age:", duration.HumanDuration(time.Since(project.GetCreationTimestamp().Time))},
+		{"Status:", status},
+		{"Finalizers:", common.EmptyAsNone(strings.Join(project.GetFinalizers(), ","))},
+		{"Pipelines:", strconv.Itoa(count)},
+	})
+	if err != nil {
+		return
+	}
+
+	members, bindingRows := getProjectMembers(bindings.Items)
+	memberRows := make([][]string, 0, len(members))
+	for _, member := range members {
+		memberRows = append(memberRows, []string{member.name, strings.Join(member.roles, ",")})
+	}
+	cmd.Println("\nMembers:")
+	if err = common.PrintTable(cmd.OutOrStdout(), []string{"MEMBER", "ROLES"}, memberRows); err != nil {
+		return
+	}
+	cmd.Println("\nRole Bindings:")
+	err = common.PrintTable(cmd.OutOrStdout(), []string{"NAME", "ROLE", "SUBJECTS"}, bindingRows)
+	return
+}
+
+// getProjectMembers returns the users which are bound to the roles, and the rows of the role bindings
+func getProjectMembers(bindings []unstructured.Unstructured) (members []projectMember, rows [][]string) {
+	roles := map[string][]string{}
+	for _, binding := range bindings {
+		roleKind, _, _ := unstructured.NestedString(binding.Object, "roleRef", "kind")
+		roleName, _, _ := unstructured.NestedString(binding.Object, "roleRef", "name")
+		role := fmt.Sprintf("%s/%s", roleKind, roleName)
+
+		subjects, _, _ := unstructured.NestedSlice(binding.Object, "subjects")
+		var subjectNames []string
+		for _, item := range subjects {
+			subject, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			kind, _ := subject["kind"].(string)
+			name, _ := subject["name"].(string)
+			subjectNames = append(subjectNames, fmt.Sprintf("%s/%s", kind, name))
+			if kind == "User" && !contains(roles[name], roleName) {
+				roles[name] = append(roles[name], roleName)
+			}
+		}
+		rows = append(rows, []string{binding.GetName(), role, common.EmptyAsNone(strings.Join(subjectNames, ","))})
+	}
+
+	for name, userRoles := range roles {
+		sort.Strings(userRoles)
+		members = append(members, projectMember{name: name, roles: userRoles})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].name < members[j].name
+	})
+	return
+}
+
+func contains(items []string, item string) bool {
+	for _, val := range items {
+		if val == item {
+			return true
+		}
+	}
+	return false
+}
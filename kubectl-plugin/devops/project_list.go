@@ -0,0 +1,83 @@
+package devops
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+)
+
+func newProjectListCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &projectListOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the DevOps projects",
+		Example: `ks devops project list
+ks devops project list --workspace my-ws`,
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.workspace, "workspace", "", "",
+		"Only list the DevOps projects of this workspace")
+	return
+}
+
+type projectListOption struct {
+	workspace string
+
+	// inner fields
+	client dynamic.Interface
+}
+
+func (o *projectListOption) preRunE(cmd *cobra.Command, _ []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	return
+}
+
+func (o *projectListOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	ctx := context.TODO()
+	listOptions := metav1.ListOptions{}
+	if o.workspace != "" {
+		listOptions.LabelSelector = labels.SelectorFromSet(labels.Set{
+			option.DevOpsProjectWorkspaceLabelKey: o.workspace,
+		}).String()
+	}
+
+	var list *unstructured.UnstructuredList
+	if list, err = o.client.Resource(types.GetDevOpsProjectSchema()).List(ctx, listOptions); err != nil {
+		err = fmt.Errorf("failed to get DevOps project list, error: %v", err)
+		return
+	}
+
+	now := time.Now()
+	rows := make([][]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		var count int
+		if count, err = getPipelineCount(ctx, o.client, item.GetName()); err != nil {
+			return
+		}
+		rows = append(rows, []string{common.EmptyAsNone(item.GetGenerateName()), item.GetName(),
+			common.EmptyAsNone(item.GetLabels()[option.DevOpsProjectWorkspaceLabelKey]), strconv.Itoa(count),
+			common.EmptyAsNone(item.GetAnnotations()[option.DevOpsProjectCreatorAnnotationKey]),
+			duration.HumanDuration(now.Sub(item.GetCreationTimestamp().Time))})
+	}
+	err = common.PrintTable(cmd.OutOrStdout(), []string{"NAME", "NAMESPACE", "WORKSPACE", "PIPELINES", "CREATOR", "AGE"}, rows)
+	return
+}
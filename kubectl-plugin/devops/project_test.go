@@ -0,0 +1,133 @@
+package devops
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestProjectLifecycle(t *testing.T) {
+	client := newFakeDynamicClient()
+	// the name of DevOpsProject is generated by the API server
+	client.PrependReactor("create", "devopsprojects", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		if obj.GetName() == "" {
+			obj.SetName(obj.GetGenerateName() + "abc")
+		}
+		return false, nil, nil
+	})
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, executeProjectCmd(client, buf, "create", "demo", "--workspace", "ws", "--skip-check",
+		"--description", "the demo", "--creator", "tester"))
+	assert.Contains(t, buf.String(), "DevOps project demo created, the namespace is demoabc")
+	project, err := option.GetDevOpsProject(context.TODO(), client, "demo")
+	assert.Nil(t, err)
+	assert.Equal(t, "tester", project.GetAnnotations()[option.DevOpsProjectCreatorAnnotationKey])
+	assert.Equal(t, "the demo", project.GetAnnotations()[option.DevOpsProjectDescriptionAnnotationKey])
+	assert.Equal(t, "ws", project.GetLabels()[option.DevOpsProjectWorkspaceLabelKey])
+	// it exists already
+	assert.NotNil(t, executeProjectCmd(client, buf, "create", "demo", "--workspace", "ws", "--skip-check"))
+
+	pip := newFakeObject("devops.kubesphere.io/v1alpha3", "Pipeline", "demoabc", "pip")
+	_, err = client.Resource(types.GetPipelineSchema()).Namespace("demoabc").Create(context.TODO(), pip, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	binding := newFakeObject("rbac.authorization.k8s.io/v1", "RoleBinding", "demoabc", "tester-admin")
+	binding.Object["roleRef"] = map[string]interface{}{"kind": "Role", "name": "admin"}
+	binding.Object["subjects"] = []interface{}{map[string]interface{}{"kind": "User", "name": "tester"}}
+	_, err = client.Resource(types.GetRoleBindingSchema()).Namespace("demoabc").Create(context.TODO(), binding, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	buf.Reset()
+	assert.Nil(t, executeProjectCmd(client, buf, "list", "--workspace", "ws"))
+	assert.Regexp(t, `demo\s+demoabc\s+ws\s+1\s+tester`, buf.String())
+	buf.Reset()
+	assert.Nil(t, executeProjectCmd(client, buf, "list", "--workspace", "fake"))
+	assert.NotContains(t, buf.String(), "demo")
+
+	buf.Reset()
+	assert.Nil(t, executeProjectCmd(client, buf, "describe", "demoabc"))
+	assert.Regexp(t, `Description:\s+the demo`, buf.String())
+	assert.Regexp(t, `Pipelines:\s+1`, buf.String())
+	assert.Regexp(t, `tester\s+admin`, buf.String())
+	assert.Regexp(t, `tester-admin\s+Role/admin\s+User/tester`, buf.String())
+
+	// the project which has Pipelines is not deleted without --force
+	assert.NotNil(t, executeProjectCmd(client, buf, "delete", "demo", "--yes"))
+	buf.Reset()
+	assert.Nil(t, executeProjectCmd(client, buf, "delete", "demo", "--yes", "--force"))
+	assert.Contains(t, buf.String(), "DevOps project demo deleted")
+	_, err = option.GetDevOpsProject(context.TODO(), client, "demo")
+	assert.NotNil(t, err)
+}
+
+func TestDeleteProjectWithFinalizers(t *testing.T) {
+	project := newFakeObject("devops.kubesphere.io/v1alpha3", "DevOpsProject", "", "demoabc")
+	project.SetGenerateName("demo")
+	project.SetFinalizers([]string{"devopsproject.finalizers.kubesphere.io"})
+	client := newFakeDynamicClient(project)
+	// the DevOpsProject is not removed until the controller removes its finalizers
+	client.PrependReactor("delete", "devopsprojects", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := client.Tracker().Get(action.GetResource(), "", action.(k8stesting.DeleteAction).GetName())
+		if err == nil {
+			now := metav1.Now()
+			obj.(*unstructured.Unstructured).SetDeletionTimestamp(&now)
+			err = client.Tracker().Update(action.GetResource(), obj, "")
+		}
+		return true, nil, err
+	})
+
+	opt := &projectDeleteOption{client: client, yes: true, timeout: 10 * time.Millisecond, interval: time.Millisecond}
+	cmd := &cobra.Command{}
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	err := opt.runE(cmd, []string{"demo"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "devopsproject.finalizers.kubesphere.io")
+
+	opt.removeFinalizers = true
+	assert.Nil(t, opt.runE(cmd, []string{"demo"}))
+	assert.Contains(t, buf.String(), "the finalizers devopsproject.finalizers.kubesphere.io of DevOps project demo are removed")
+	project, err = client.Resource(types.GetDevOpsProjectSchema()).Get(context.TODO(), "demoabc", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, project.GetFinalizers())
+}
+
+func executeProjectCmd(client dynamic.Interface, buf *bytes.Buffer, args ...string) error {
+	cmd := newProjectCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetOut(buf)
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		types.GetPipelineSchema():      "PipelineList",
+		types.GetDevOpsProjectSchema(): "DevOpsProjectList",
+		types.GetRoleBindingSchema():   "RoleBindingList",
+	}, objects...)
+}
+
+func newFakeObject(apiVersion, kind, ns, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(ns)
+	obj.SetName(name)
+	return obj
+}
@@ -0,0 +1,51 @@
+package devops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// NewDevOpsCmd returns the command of KubeSphere DevOps
+func NewDevOpsCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:   "devops",
+		Short: "Manage the resources of KubeSphere DevOps",
+	}
+
+	cmd.AddCommand(newProjectCmd(client))
+	return
+}
+
+func newProjectCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:     "project",
+		Aliases: []string{"proj"},
+		Short:   "Manage the DevOps projects",
+		Long: `Manage the DevOps projects
+A DevOps project is identified by its name, which is the generateName of the DevOpsProject,
+or the generated namespace.`,
+	}
+
+	cmd.AddCommand(newProjectCreateCmd(client),
+		newProjectListCmd(client),
+		newProjectDescribeCmd(client),
+		newProjectDeleteCmd(client))
+	return
+}
+
+// getPipelineCount returns the number of Pipelines in the namespace of a DevOps project
+func getPipelineCount(ctx context.Context, client dynamic.Interface, ns string) (count int, err error) {
+	var list *unstructured.UnstructuredList
+	if list, err = client.Resource(types.GetPipelineSchema()).Namespace(ns).List(ctx, metav1.ListOptions{}); err != nil {
+		err = fmt.Errorf("failed to get Pipeline list in namespace '%s', error: %v", ns, err)
+		return
+	}
+	count = len(list.Items)
+	return
+}
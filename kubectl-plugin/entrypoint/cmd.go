@@ -7,6 +7,7 @@ import (
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/component"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/config"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/devops"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/install"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/registry"
@@ -58,6 +59,7 @@ See also https://github.com/kubesphere/kubesphere`,
 
 	cmd.AddCommand(user.NewUserCmd(client),
 		pipeline.NewPipelineCmd(client),
+		devops.NewDevOpsCmd(client),
 		update.NewUpdateCmd(client),
 		extver.NewVersionCmd("kubesphere-sigs", "ks", "kubectl-ks", nil),
 		pkg.NewCompletionCmd(cmd),
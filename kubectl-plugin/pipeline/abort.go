@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newPipelineAbortCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineAbortOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:     "abort",
+		Aliases: []string{"cancel", "stop"},
+		Short:   "Abort the running PipelineRuns",
+		Long: `Abort the running PipelineRuns
+The PipelineRun is selected from the running ones of a Pipeline if it's not given.`,
+		Example: `ks pip abort devops-ns my-pipeline-xxxxx
+ks pip abort devops-ns --pipeline my-pipeline --all-running`,
+		Args:    cobra.MaximumNArgs(2),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.pipeline, "pipeline", "p", "",
+		"The Pipeline to select the PipelineRuns from")
+	flags.BoolVarP(&opt.allRunning, "all-running", "", false,
+		"Abort all the running PipelineRuns of the Pipeline")
+	opt.addDevOpsAPIFlags(flags)
+	return
+}
+
+type pipelineAbortOption struct {
+	pipeline   string
+	allRunning bool
+	devopsAPIOption
+
+	// inner fields
+	client       dynamic.Interface
+	namespace    string
+	pipelineRuns []string
+}
+
+func (o *pipelineAbortOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	if o.allRunning {
+		if len(args) > 1 {
+			err = fmt.Errorf("the PipelineRun name cannot be used together with --all-running")
+			return
+		}
+		if o.namespace, err = getNamespace(o.client, args); err != nil {
+			return
+		}
+		if o.pipeline, err = choosePipeline(o.client, o.namespace, o.pipeline); err != nil {
+			return
+		}
+
+		var items []unstructured.Unstructured
+		if items, err = getPipelineRunList(context.TODO(), o.client, o.namespace, o.pipeline); err != nil {
+			return
+		}
+		for i := range items {
+			if !isCompletedPhase(getPipelineRunPhase(&items[i])) {
+				o.pipelineRuns = append(o.pipelineRuns, items[i].GetName())
+			}
+		}
+	} else {
+		var pipelineRun string
+		if o.namespace, pipelineRun, err = choosePipelineRun(o.client, args, o.pipeline, true); err != nil {
+			return
+		}
+		o.pipelineRuns = []string{pipelineRun}
+	}
+	err = o.initDevopsClient()
+	return
+}
+
+func (o *pipelineAbortOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	if len(o.pipelineRuns) == 0 {
+		cmd.Printf("no running PipelineRuns found of Pipeline %s/%s\n", o.namespace, o.pipeline)
+		return
+	}
+
+	var failed int
+	for _, name := range o.pipelineRuns {
+		if abortErr := o.abort(context.TODO(), name); abortErr != nil {
+			cmd.PrintErrf("failed to abort PipelineRun %s/%s, error: %v\n", o.namespace, name, abortErr)
+			failed++
+			continue
+		}
+		cmd.Printf("PipelineRun %s/%s aborted\n", o.namespace, name)
+	}
+	if failed > 0 {
+		err = fmt.Errorf("failed to abort %d PipelineRuns", failed)
+	}
+	return
+}
+
+func (o *pipelineAbortOption) abort(ctx context.Context, name string) (err error) {
+	var pipelineRun *unstructured.Unstructured
+	if pipelineRun, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(o.namespace).Get(ctx, name,
+		metav1.GetOptions{}); err != nil {
+		return
+	}
+	if isCompletedPhase(getPipelineRunPhase(pipelineRun)) {
+		err = fmt.Errorf("it's already %s", getPipelineRunPhase(pipelineRun))
+		return
+	}
+
+	runID := pipelineRun.GetAnnotations()[option.PipelinerunIdAnnotationKey]
+	if runID == "" {
+		err = fmt.Errorf("it has no Jenkins run ID, it might be not started yet")
+		return
+	}
+	err = o.stopRun(ctx, o.namespace, getPipelineRunPipeline(pipelineRun), getPipelineRunBranch(pipelineRun), runID)
+	return
+}
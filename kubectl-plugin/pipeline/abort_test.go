@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPipelineAbort(t *testing.T) {
+	var stopped []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stopped = append(stopped, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	newRunningRun := func(name, runID, branch string) *unstructured.Unstructured {
+		run := newFakePipelineRun(name, "pip", now)
+		_ = unstructured.SetNestedField(run.Object, option.PipelinerunPhaseRunning, "status", "phase")
+		if branch != "" {
+			_ = unstructured.SetNestedField(run.Object, branch, "spec", "scm", "refName")
+		}
+		run.SetAnnotations(map[string]string{option.PipelinerunIdAnnotationKey: runID})
+		return run
+	}
+	client := newFakeDynamicClient(newFakePipeline("ns", "pip", ""),
+		newRunningRun("run-1", "1", ""), newRunningRun("run-2", "2", "main"),
+		newFakeCompletedRun("run-3", option.PipelinerunPhaseSucceeded, "a", now, time.Minute))
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	buf := bytes.NewBuffer(nil)
+	cmd := newPipelineAbortCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "run-1", "--devops-api-host", host})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "PipelineRun ns/run-1 aborted")
+	assert.Equal(t, []string{"/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/runs/1/stop"}, stopped)
+
+	stopped = nil
+	buf.Reset()
+	cmd = newPipelineAbortCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "--pipeline", "pip", "--all-running", "--devops-api-host", host})
+	assert.Nil(t, cmd.Execute())
+	assert.ElementsMatch(t, []string{
+		"/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/runs/1/stop",
+		"/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/branches/main/runs/2/stop",
+	}, stopped)
+
+	// a completed PipelineRun cannot be aborted
+	cmd = newPipelineAbortCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"ns", "run-3", "--devops-api-host", host})
+	assert.NotNil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "it's already Succeeded")
+}
@@ -0,0 +1,467 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// applyFieldManager is the field manager of the server-side apply
+	applyFieldManager = "ks"
+	// pipelineManagedByLabelKey marks the Pipelines which are owned by ks pip apply
+	pipelineManagedByLabelKey = "app.kubernetes.io/managed-by"
+)
+
+func newPipelineApplyCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineApplyOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Create or update Pipelines from files via the server-side apply",
+		Long: `Create or update Pipelines from files via the server-side apply
+The files could be the Pipeline YAML, the Jenkinsfile is read from the .groovy file which has the same name if it's empty.
+They could be the simple format as well:
+
+name: build
+description: build the project
+jenkinsfile: build.groovy
+discarder:
+  daysToKeep: 7
+  numToKeep: 10
+disableConcurrent: true
+
+The applied Pipelines are labeled with ` + pipelineManagedByLabelKey + `=` + applyFieldManager + `, only these ones are deleted by --prune.
+The fields which are managed by others are not overwritten unless --force-conflicts is given.`,
+		Example: `ks pip apply -f ./pipelines/ --project my-project
+ks pip apply -f ./pipelines/ -n devops-ns --prune --dry-run`,
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringArrayVarP(&opt.files, "filename", "f", nil,
+		"The files or directories which contain the Pipelines")
+	flags.StringVarP(&opt.namespace, "namespace", "n", "",
+		"The namespace of the Pipelines, take the one of each Pipeline if it's empty")
+	flags.StringVarP(&opt.project, "project", "", "",
+		"The DevOps project of the Pipelines, it's the name or generateName of the DevOpsProject")
+	flags.BoolVarP(&opt.prune, "prune", "", false,
+		"Delete the Pipelines which are applied by ks before but no longer defined in the files")
+	flags.BoolVarP(&opt.dryRun, "dry-run", "", false,
+		"Only print the differences without changing anything")
+	flags.BoolVarP(&opt.forceConflicts, "force-conflicts", "", false,
+		"Take the ownership of the fields which are managed by others when there are conflicts")
+	_ = cmd.MarkFlagRequired("filename")
+	return
+}
+
+type pipelineApplyOption struct {
+	files          []string
+	namespace      string
+	project        string
+	prune          bool
+	dryRun         bool
+	forceConflicts bool
+
+	// inner fields
+	client dynamic.Interface
+}
+
+// pipelineSpecFile is the simple format of a Pipeline
+type pipelineSpecFile struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	// Jenkinsfile is the path of the Jenkinsfile, it's relative to the spec file
+	Jenkinsfile string `json:"jenkinsfile"`
+	Discarder   *struct {
+		DaysToKeep int `json:"daysToKeep"`
+		NumToKeep  int `json:"numToKeep"`
+	} `json:"discarder,omitempty"`
+	DisableConcurrent bool `json:"disableConcurrent,omitempty"`
+}
+
+func (o *pipelineApplyOption) preRunE(cmd *cobra.Command, _ []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	if o.project != "" {
+		var project *unstructured.Unstructured
+		if project, err = option.GetDevOpsProject(context.TODO(), o.client, o.project); err != nil {
+			return
+		}
+		o.namespace = project.GetName()
+	}
+	return
+}
+
+func (o *pipelineApplyOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	var pips []*unstructured.Unstructured
+	if pips, err = o.loadPipelines(cmd); err != nil {
+		return
+	}
+
+	ctx := context.TODO()
+	// the Pipelines which are defined in the files of each namespace
+	defined := map[string]map[string]bool{}
+	if o.namespace != "" {
+		defined[o.namespace] = map[string]bool{}
+	}
+	for _, pip := range pips {
+		ns := pip.GetNamespace()
+		if defined[ns] == nil {
+			defined[ns] = map[string]bool{}
+		}
+		if defined[ns][pip.GetName()] {
+			err = fmt.Errorf("pipeline %s/%s is defined more than once", ns, pip.GetName())
+			return
+		}
+		defined[ns][pip.GetName()] = true
+	}
+
+	for _, pip := range pips {
+		if err = o.apply(ctx, cmd, pip); err != nil {
+			return
+		}
+	}
+
+	if o.prune {
+		namespaces := make([]string, 0, len(defined))
+		for ns := range defined {
+			namespaces = append(namespaces, ns)
+		}
+		sort.Strings(namespaces)
+		for _, ns := range namespaces {
+			if err = o.pruneNamespace(ctx, cmd, ns, defined[ns]); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (o *pipelineApplyOption) apply(ctx context.Context, cmd *cobra.Command, pip *unstructured.Unstructured) (err error) {
+	ns, name := pip.GetNamespace(), pip.GetName()
+	var existing *unstructured.Unstructured
+	if existing, err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if !errors.IsNotFound(err) {
+			err = fmt.Errorf("failed to get Pipeline %s/%s, error: %v", ns, name, err)
+			return
+		}
+		existing, err = nil, nil
+	}
+
+	var from, to string
+	if existing != nil {
+		from = toApplyView(existing, pip)
+	}
+	to = toApplyView(pip, pip)
+	if from == to {
+		cmd.Printf("pipeline %s/%s unchanged\n", ns, name)
+		return
+	}
+	cmd.Print(unifiedDiff(fmt.Sprintf("%s/%s", ns, name), from, to))
+
+	action := "configured"
+	if existing == nil {
+		action = "created"
+	}
+	if o.dryRun {
+		cmd.Printf("pipeline %s/%s %s (dry run)\n", ns, name, action)
+		return
+	}
+
+	if _, err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).Apply(ctx, name, pip, metav1.ApplyOptions{
+		FieldManager: applyFieldManager,
+		Force:        o.forceConflicts,
+	}); err != nil {
+		if errors.IsConflict(err) {
+			err = fmt.Errorf("failed to apply Pipeline %s/%s due to conflicts with other managers, "+
+				"resolve them or take the ownership via --force-conflicts, error: %v", ns, name, err)
+		} else {
+			err = fmt.Errorf("failed to apply Pipeline %s/%s, error: %v", ns, name, err)
+		}
+		return
+	}
+	cmd.Printf("pipeline %s/%s %s\n", ns, name, action)
+	return
+}
+
+// pruneNamespace deletes the Pipelines which were applied by ks but are not defined anymore
+func (o *pipelineApplyOption) pruneNamespace(ctx context.Context, cmd *cobra.Command, ns string, defined map[string]bool) (err error) {
+	var list *unstructured.UnstructuredList
+	if list, err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", pipelineManagedByLabelKey, applyFieldManager),
+	}); err != nil {
+		err = fmt.Errorf("failed to get Pipeline list in '%s', error: %v", ns, err)
+		return
+	}
+
+	for _, item := range list.Items {
+		if defined[item.GetName()] {
+			continue
+		}
+		if o.dryRun {
+			cmd.Printf("pipeline %s/%s pruned (dry run)\n", ns, item.GetName())
+			continue
+		}
+		if err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).Delete(ctx, item.GetName(),
+			metav1.DeleteOptions{}); err != nil {
+			err = fmt.Errorf("failed to delete Pipeline %s/%s, error: %v", ns, item.GetName(), err)
+			return
+		}
+		cmd.Printf("pipeline %s/%s pruned\n", ns, item.GetName())
+	}
+	return
+}
+
+// loadPipelines reads the Pipelines from the files or directories
+func (o *pipelineApplyOption) loadPipelines(cmd *cobra.Command) (pips []*unstructured.Unstructured, err error) {
+	var files []string
+	for _, file := range o.files {
+		var info os.FileInfo
+		if info, err = os.Stat(file); err != nil {
+			return
+		}
+		if !info.IsDir() {
+			files = append(files, file)
+			continue
+		}
+
+		for _, ext := range []string{".yaml", ".yml"} {
+			var found []string
+			if found, err = listFiles(file, ext); err != nil {
+				return
+			}
+			files = append(files, found...)
+		}
+	}
+
+	for _, file := range files {
+		var filePips []*unstructured.Unstructured
+		if filePips, err = readPipelineFile(cmd, file); err != nil {
+			err = fmt.Errorf("failed to read Pipelines from %s, error: %v", file, err)
+			return
+		}
+
+		for _, pip := range filePips {
+			if o.namespace != "" {
+				pip.SetNamespace(o.namespace)
+			} else if pip.GetNamespace() == "" {
+				err = fmt.Errorf("the namespace of Pipeline %s is missing, please provide it via --namespace or --project",
+					pip.GetName())
+				return
+			}
+
+			labels := pip.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			if managedBy, ok := labels[pipelineManagedByLabelKey]; ok && managedBy != applyFieldManager {
+				err = fmt.Errorf("the label %s of Pipeline %s in %s is '%s', it should be '%s' or empty",
+					pipelineManagedByLabelKey, pip.GetName(), file, managedBy, applyFieldManager)
+				return
+			}
+			labels[pipelineManagedByLabelKey] = applyFieldManager
+			pip.SetLabels(labels)
+			pips = append(pips, pip)
+		}
+	}
+	return
+}
+
+// readPipelineFile reads the Pipelines from a YAML file which might contain multiple documents
+func readPipelineFile(cmd *cobra.Command, file string) (pips []*unstructured.Unstructured, err error) {
+	var data []byte
+	if data, err = os.ReadFile(file); err != nil {
+		return
+	}
+
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := map[string]interface{}{}
+		if err = decoder.Decode(&obj); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		if len(obj) == 0 {
+			continue
+		}
+
+		var pip *unstructured.Unstructured
+		switch kind, _ := obj["kind"].(string); kind {
+		case "Pipeline":
+			pip = &unstructured.Unstructured{Object: obj}
+			err = setJenkinsfileFromFile(pip, strings.TrimSuffix(file, filepath.Ext(file))+".groovy")
+		case "":
+			pip, err = parsePipelineSpecFile(obj, filepath.Dir(file))
+		default:
+			cmd.PrintErrf("%s %s in %s is not a Pipeline, skipped\n", kind, getNestedName(obj), file)
+			continue
+		}
+		if err != nil {
+			return
+		}
+		pips = append(pips, pip)
+	}
+}
+
+// setJenkinsfileFromFile sets the Jenkinsfile of a Pipeline from a file if it's empty and the file exists
+func setJenkinsfileFromFile(pip *unstructured.Unstructured, file string) (err error) {
+	if pType, _, _ := unstructured.NestedString(pip.Object, "spec", "type"); pType != option.NoScmPipelineType {
+		return
+	}
+	if script, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "jenkinsfile"); script != "" {
+		return
+	}
+
+	var data []byte
+	if data, err = os.ReadFile(file); err == nil {
+		err = unstructured.SetNestedField(pip.Object, string(data), "spec", "pipeline", "jenkinsfile")
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	return
+}
+
+// parsePipelineSpecFile converts the simple format to a Pipeline
+func parsePipelineSpecFile(obj map[string]interface{}, dir string) (pip *unstructured.Unstructured, err error) {
+	var data []byte
+	if data, err = yaml.Marshal(obj); err != nil {
+		return
+	}
+	spec := &pipelineSpecFile{}
+	if err = yaml.UnmarshalStrict(data, spec); err != nil {
+		return
+	}
+	if spec.Name == "" {
+		err = fmt.Errorf("the name of Pipeline is missing")
+		return
+	}
+
+	content := map[string]interface{}{
+		"name": spec.Name,
+	}
+	if spec.Description != "" {
+		content["description"] = spec.Description
+	}
+	if spec.Jenkinsfile != "" {
+		file := spec.Jenkinsfile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		if data, err = os.ReadFile(file); err != nil {
+			return
+		}
+		content["jenkinsfile"] = string(data)
+	}
+	if spec.Discarder != nil {
+		content["discarder"] = map[string]interface{}{
+			"days_to_keep": strconv.Itoa(spec.Discarder.DaysToKeep),
+			"num_to_keep":  strconv.Itoa(spec.Discarder.NumToKeep),
+		}
+	}
+	if spec.DisableConcurrent {
+		content["disable_concurrent"] = true
+	}
+
+	pip = &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"type":     option.NoScmPipelineType,
+			"pipeline": content,
+		},
+	}}
+	pip.SetAPIVersion(types.GetPipelineSchema().GroupVersion().String())
+	pip.SetKind("Pipeline")
+	pip.SetName(spec.Name)
+	pip.SetLabels(spec.Labels)
+	return
+}
+
+// toApplyView returns the YAML of the fields which are managed by the desired Pipeline,
+// it's used to compare the existing Pipeline with the desired one.
+// The fields which are not in the desired Pipeline are dropped, such as the ones defaulted by the server.
+func toApplyView(obj, desired *unstructured.Unstructured) string {
+	metadata := map[string]interface{}{
+		"name": obj.GetName(),
+	}
+	if labels := subsetMap(obj.GetLabels(), desired.GetLabels()); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if annotations := subsetMap(obj.GetAnnotations(), desired.GetAnnotations()); len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	view := map[string]interface{}{
+		"metadata": metadata,
+		"spec":     subsetValue(obj.Object["spec"], desired.Object["spec"]),
+	}
+	data, _ := yaml.Marshal(view)
+	return string(data)
+}
+
+// subsetMap returns the items of the map whose keys exist in the reference
+func subsetMap(items, reference map[string]string) (result map[string]string) {
+	result = map[string]string{}
+	for key := range reference {
+		if val, ok := items[key]; ok {
+			result[key] = val
+		}
+	}
+	return
+}
+
+// subsetValue returns the value which has only the map keys existing in the reference, it works recursively
+func subsetValue(value, reference interface{}) interface{} {
+	switch ref := reference.(type) {
+	case map[string]interface{}:
+		items, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		result := map[string]interface{}{}
+		for key, refVal := range ref {
+			if val, ok := items[key]; ok {
+				result[key] = subsetValue(val, refVal)
+			}
+		}
+		return result
+	case []interface{}:
+		items, ok := value.([]interface{})
+		if !ok || len(items) != len(ref) {
+			return value
+		}
+		result := make([]interface{}, len(items))
+		for i := range items {
+			result[i] = subsetValue(items[i], ref[i])
+		}
+		return result
+	}
+	return value
+}
+
+func getNestedName(obj map[string]interface{}) (name string) {
+	name, _, _ = unstructured.NestedString(obj, "metadata", "name")
+	return
+}
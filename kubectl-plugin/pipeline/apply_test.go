@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+func TestPipelineApply(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(path.Join(dir, "build.yaml"), []byte(`name: build
+description: build the project
+jenkinsfile: build.groovy
+discarder:
+  daysToKeep: 7
+  numToKeep: 10
+---
+name: test
+`), 0640))
+	assert.Nil(t, os.WriteFile(path.Join(dir, "build.groovy"), []byte("pipeline {}"), 0640))
+
+	deploy := newFakePipeline("", "deploy", "")
+	deploy.SetUID("")
+	deploy.SetResourceVersion("")
+	unstructured.RemoveNestedField(deploy.Object, "status")
+	data, err := yaml.Marshal(deploy.Object)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(path.Join(dir, "deploy.yaml"), data, 0640))
+	assert.Nil(t, os.WriteFile(path.Join(dir, "deploy.groovy"), []byte("pipeline { agent any }"), 0640))
+	assert.Nil(t, os.WriteFile(path.Join(dir, "readme.md"), []byte("not a Pipeline"), 0640))
+
+	stale := newFakePipeline("ns", "stale", "")
+	stale.SetLabels(map[string]string{pipelineManagedByLabelKey: applyFieldManager})
+	client := newFakeDynamicClient(newFakePipeline("ns", "test", ""), stale, newFakePipeline("ns", "manual", ""))
+	addFakeApplyReactor(client)
+
+	// nothing is changed in the dry run mode
+	buf := bytes.NewBuffer(nil)
+	cmd := newPipelineApplyCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-f", dir, "-n", "ns", "--prune", "--dry-run"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "+  name: build")
+	assert.Contains(t, buf.String(), "pipeline ns/build created (dry run)")
+	assert.Contains(t, buf.String(), "pipeline ns/test configured (dry run)")
+	assert.Contains(t, buf.String(), "pipeline ns/stale pruned (dry run)")
+	assert.ElementsMatch(t, []string{"test", "stale", "manual"}, getFakePipelineNames(t, client))
+
+	buf.Reset()
+	cmd = newPipelineApplyCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-f", dir, "-n", "ns", "--prune"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "pipeline ns/deploy created")
+	assert.Contains(t, buf.String(), "pipeline ns/stale pruned")
+	assert.ElementsMatch(t, []string{"build", "test", "deploy", "manual"}, getFakePipelineNames(t, client))
+
+	build, err := client.Resource(types.GetPipelineSchema()).Namespace("ns").Get(context.TODO(), "build", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, applyFieldManager, build.GetLabels()[pipelineManagedByLabelKey])
+	script, _, _ := unstructured.NestedString(build.Object, "spec", "pipeline", "jenkinsfile")
+	assert.Equal(t, "pipeline {}", script)
+	days, _, _ := unstructured.NestedString(build.Object, "spec", "pipeline", "discarder", "days_to_keep")
+	assert.Equal(t, "7", days)
+	deployed, err := client.Resource(types.GetPipelineSchema()).Namespace("ns").Get(context.TODO(), "deploy", metav1.GetOptions{})
+	assert.Nil(t, err)
+	script, _, _ = unstructured.NestedString(deployed.Object, "spec", "pipeline", "jenkinsfile")
+	assert.Equal(t, "pipeline { agent any }", script)
+
+	// apply again without any changes
+	buf.Reset()
+	cmd = newPipelineApplyCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-f", path.Join(dir, "build.yaml"), "-n", "ns"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "pipeline ns/build unchanged")
+
+	// the fields which are defaulted by the server are not changes
+	_ = unstructured.SetNestedMap(build.Object, map[string]interface{}{"interval": ""}, "spec", "pipeline", "timer_trigger")
+	_, err = client.Resource(types.GetPipelineSchema()).Namespace("ns").Update(context.TODO(), build, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	buf.Reset()
+	cmd = newPipelineApplyCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-f", path.Join(dir, "build.yaml"), "-n", "ns"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "pipeline ns/build unchanged")
+	assert.NotContains(t, buf.String(), "timer_trigger")
+
+	// the namespace is required
+	cmd = newPipelineApplyCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"-f", dir})
+	assert.NotNil(t, cmd.Execute())
+
+	// the unknown fields are not allowed in the simple format
+	assert.Nil(t, os.WriteFile(path.Join(dir, "wrong.yaml"), []byte("name: wrong\nfake: true\n"), 0640))
+	cmd = newPipelineApplyCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"-f", dir, "-n", "ns"})
+	assert.NotNil(t, cmd.Execute())
+}
+
+func TestPipelineApplyConflicts(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(path.Join(dir, "build.yaml"), []byte("name: build\n"), 0640))
+
+	client := newFakeDynamicClient()
+	client.PrependReactor("patch", "pipelines", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewConflict(types.GetPipelineSchema().GroupResource(), "build",
+			fmt.Errorf("conflict with \"kubectl\""))
+	})
+	cmd := newPipelineApplyCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetOut(bytes.NewBuffer(nil))
+	cmd.SetArgs([]string{"-f", dir, "-n", "ns"})
+	err := cmd.Execute()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "--force-conflicts")
+
+	// the label which is managed by others is not overwritten
+	assert.Nil(t, os.WriteFile(path.Join(dir, "build.yaml"), []byte(`name: build
+labels:
+  app.kubernetes.io/managed-by: helm
+`), 0640))
+	cmd = newPipelineApplyCmd(newFakeDynamicClient())
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"-f", dir, "-n", "ns"})
+	err = cmd.Execute()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "app.kubernetes.io/managed-by")
+}
+
+// addFakeApplyReactor replaces or creates the object when applying it,
+// the fake client cannot apply the unstructured objects
+func addFakeApplyReactor(client *fake.FakeDynamicClient) {
+	client.PrependReactor("patch", "pipelines", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(k8stesting.PatchAction)
+		if patchAction.GetPatchType() != k8stypes.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		tracker := client.Tracker()
+		if _, err := tracker.Get(action.GetResource(), action.GetNamespace(), patchAction.GetName()); err == nil {
+			return true, obj, tracker.Update(action.GetResource(), obj, action.GetNamespace())
+		}
+		return true, obj, tracker.Create(action.GetResource(), obj, action.GetNamespace())
+	})
+}
@@ -0,0 +1,174 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newPipelineCloneCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineCloneOption{
+		client:          client,
+		getTargetClient: getContextDynamicClient,
+	}
+	cmd = &cobra.Command{
+		Use:   "clone",
+		Short: "Clone a Pipeline into another DevOps project",
+		Long: `Clone a Pipeline into another DevOps project
+The target DevOps project will be created if it does not exist. The credentials are not copied,
+the ones which are referenced by the Pipeline should exist in the target DevOps project.`,
+		Example: `ks pip clone devops-ns/my-pipeline --to-project another
+ks pip clone devops-ns/my-pipeline --to-project another --to-context prod --name new-pipeline`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.toProject, "to-project", "", "",
+		"The target DevOps project name, it's the generateName of the DevOpsProject")
+	flags.StringVarP(&opt.toContext, "to-context", "", "",
+		"The kubeconfig context of the target cluster, take the current one if it's empty")
+	flags.StringVarP(&opt.toWorkspace, "to-workspace", "", "",
+		"The workspace of the target DevOps project, take the one of source DevOps project if it's empty")
+	flags.StringVarP(&opt.name, "name", "", "",
+		"The name of the new Pipeline, take the source one if it's empty")
+	flags.BoolVarP(&opt.skipCheck, "skip-check", "", false, "Skip the workspace check")
+	_ = cmd.MarkFlagRequired("to-project")
+	return
+}
+
+type pipelineCloneOption struct {
+	toProject   string
+	toContext   string
+	toWorkspace string
+	name        string
+	skipCheck   bool
+
+	// inner fields
+	client          dynamic.Interface
+	getTargetClient func(kubeContext string) (dynamic.Interface, error)
+	namespace       string
+	pipeline        string
+}
+
+// getContextDynamicClient returns the dynamic client of a kubeconfig context
+func getContextDynamicClient(kubeContext string) (dynamic.Interface, error) {
+	factory := &common.ClientFactory{}
+	factory.SetContext(kubeContext)
+	return factory.GetDynamicClient()
+}
+
+func (o *pipelineCloneOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	items := strings.Split(args[0], "/")
+	if len(items) != 2 || items[0] == "" || items[1] == "" {
+		err = fmt.Errorf("the source Pipeline should be like: namespace/pipeline, got: %s", args[0])
+		return
+	}
+	o.namespace, o.pipeline = items[0], items[1]
+	if o.name == "" {
+		o.name = o.pipeline
+	}
+	return
+}
+
+func (o *pipelineCloneOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	ctx := context.TODO()
+	var project *unstructured.Unstructured
+	if project, err = option.GetDevOpsProject(ctx, o.client, o.namespace); err != nil {
+		return
+	}
+	var source *unstructured.Unstructured
+	if source, err = o.client.Resource(types.GetPipelineSchema()).Namespace(project.GetName()).Get(ctx, o.pipeline,
+		metav1.GetOptions{}); err != nil {
+		err = fmt.Errorf("cannot get pipeline %s/%s, error: %v", project.GetName(), o.pipeline, err)
+		return
+	}
+
+	targetClient := o.client
+	if o.toContext != "" {
+		if targetClient, err = o.getTargetClient(o.toContext); err != nil {
+			err = fmt.Errorf("failed to get the Kubernetes client of context '%s', error: %v", o.toContext, err)
+			return
+		}
+	}
+
+	createOpt := &option.PipelineCreateOption{
+		Client:    targetClient,
+		Project:   o.toProject,
+		Workspace: o.toWorkspace,
+		SkipCheck: o.skipCheck,
+	}
+	if createOpt.Workspace == "" {
+		createOpt.Workspace = project.GetLabels()["kubesphere.io/workspace"]
+	}
+	var wsID string
+	if !o.skipCheck {
+		var ws *unstructured.Unstructured
+		if ws, err = createOpt.CheckWorkspace(); err != nil {
+			err = fmt.Errorf("cannot find workspace %s, error: %v", createOpt.Workspace, err)
+			return
+		}
+		wsID = string(ws.GetUID())
+	}
+	var targetProject *unstructured.Unstructured
+	if targetProject, err = createOpt.CheckDevOpsProject(wsID); err != nil {
+		err = fmt.Errorf("cannot find devopsProject %s, error %v", o.toProject, err)
+		return
+	}
+	ns := targetProject.GetName()
+	if o.toContext == "" && ns == project.GetName() && o.name == o.pipeline {
+		err = fmt.Errorf("the target Pipeline is the same as the source one, please provide a new name")
+		return
+	}
+
+	pip := clonePipeline(source, ns, o.name)
+	// the credentials are checked after cloning, parse them before it to avoid failing with a cloned Pipeline
+	ids, parseErr := getPipelineCredentialIDs(pip)
+	if _, err = targetClient.Resource(types.GetPipelineSchema()).Namespace(ns).Create(ctx, pip, metav1.CreateOptions{}); err != nil {
+		err = fmt.Errorf("failed to create Pipeline %s/%s, error: %v", ns, o.name, err)
+		return
+	}
+	cmd.Printf("Pipeline %s/%s cloned to %s/%s\n", project.GetName(), o.pipeline, ns, o.name)
+
+	if parseErr != nil {
+		cmd.PrintErrf("failed to parse the Jenkinsfile of Pipeline %s, its credentials are not checked, error: %v\n",
+			o.name, parseErr)
+	}
+	for _, id := range ids {
+		if _, getErr := targetClient.Resource(types.GetSecretSchema()).Namespace(ns).Get(ctx, id,
+			metav1.GetOptions{}); getErr != nil {
+			cmd.PrintErrf("credential '%s' is not found in '%s', it must exist before running the Pipeline\n", id, ns)
+		} else {
+			cmd.Printf("credential '%s' found in '%s'\n", id, ns)
+		}
+	}
+	return
+}
+
+// clonePipeline returns a copy of the Pipeline without the cluster-specific metadata and status.
+// The spec, such as the Jenkinsfile, parameters, triggers and discarder, is kept as it is.
+func clonePipeline(source *unstructured.Unstructured, ns, name string) (pip *unstructured.Unstructured) {
+	pip = cleanObject(source)
+	pip.SetNamespace(ns)
+	pip.SetName(name)
+
+	for _, contentKey := range []string{"pipeline", "multi_branch_pipeline"} {
+		if _, ok, _ := unstructured.NestedFieldNoCopy(pip.Object, "spec", contentKey, "name"); ok {
+			_ = unstructured.SetNestedField(pip.Object, name, "spec", contentKey, "name")
+		}
+	}
+	return
+}
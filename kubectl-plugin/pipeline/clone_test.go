@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func TestPipelineClone(t *testing.T) {
+	source := newFakePipeline("proj-abc", "pip", "pipeline { stages { stage('a') { steps { git(credentialsId: 'git') } } } }")
+	source.SetFinalizers([]string{"pipeline.finalizers.kubesphere.io"})
+	_ = unstructured.SetNestedStringMap(source.Object, map[string]string{"days_to_keep": "7", "num_to_keep": "10"},
+		"spec", "pipeline", "discarder")
+	client := newFakeDynamicClient(newFakeDevOpsProject("proj-abc", "proj", "ws"), source)
+	targetClient := newFakeDynamicClient(newFakeDevOpsProject("another-xyz", "another", "ws"))
+
+	opt := &pipelineCloneOption{
+		toProject: "another",
+		toContext: "target",
+		name:      "new",
+		skipCheck: true,
+		client:    client,
+		getTargetClient: func(kubeContext string) (dynamic.Interface, error) {
+			assert.Equal(t, "target", kubeContext)
+			return targetClient, nil
+		},
+	}
+	cmd := &cobra.Command{}
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	assert.Nil(t, opt.preRunE(cmd, []string{"proj/pip"}))
+	assert.Nil(t, opt.runE(cmd, nil))
+	assert.Contains(t, buf.String(), "Pipeline proj-abc/pip cloned to another-xyz/new")
+	assert.Contains(t, buf.String(), "credential 'git' is not found in 'another-xyz'")
+
+	pip, err := targetClient.Resource(types.GetPipelineSchema()).Namespace("another-xyz").Get(context.TODO(), "new", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, pip.GetUID())
+	assert.Empty(t, pip.GetFinalizers())
+	name, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "name")
+	assert.Equal(t, "new", name)
+	days, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "discarder", "days_to_keep")
+	assert.Equal(t, "7", days)
+	script, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "jenkinsfile")
+	assert.Contains(t, script, "credentialsId: 'git'")
+
+	// the Pipeline is cloned even if its Jenkinsfile cannot be parsed
+	broken := newFakePipeline("proj-abc", "broken", "pipeline { stages { stage('a') { steps { echo 'a } } } }")
+	_, err = client.Resource(types.GetPipelineSchema()).Namespace("proj-abc").Create(context.TODO(), broken, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	buf.Reset()
+	opt.name = ""
+	assert.Nil(t, opt.preRunE(cmd, []string{"proj/broken"}))
+	assert.Nil(t, opt.runE(cmd, nil))
+	assert.Contains(t, buf.String(), "Pipeline proj-abc/broken cloned to another-xyz/broken")
+	assert.Contains(t, buf.String(), "failed to parse the Jenkinsfile of Pipeline broken")
+
+	// the error of the kubeconfig context is returned
+	opt.getTargetClient = func(string) (dynamic.Interface, error) {
+		return nil, fmt.Errorf("context \"target\" does not exist")
+	}
+	err = opt.runE(cmd, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `context "target" does not exist`)
+
+	// cannot clone to itself
+	cmd = newPipelineCloneCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"proj/pip", "--to-project", "proj", "--skip-check"})
+	assert.NotNil(t, cmd.Execute())
+
+	cmd = newPipelineCloneCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"pip", "--to-project", "proj"})
+	assert.NotNil(t, cmd.Execute())
+}
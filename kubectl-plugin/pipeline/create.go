@@ -2,6 +2,8 @@ package pipeline
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/tpl"
@@ -11,6 +13,7 @@ import (
 
 type innerPipelineCreateOption struct {
 	option.PipelineCreateOption
+	skipLint bool
 }
 
 func newPipelineCreateCmd(client dynamic.Interface) (cmd *cobra.Command) {
@@ -25,8 +28,16 @@ func newPipelineCreateCmd(client dynamic.Interface) (cmd *cobra.Command) {
 		Short: "Create a Pipeline in the KubeSphere cluster",
 		Long: `Create a Pipeline in the KubeSphere cluster
 You can create a Pipeline with a java, go template. Before you do that, please make sure the workspace exists.
-KubeSphere supports multiple types Pipeline. Currently, this CLI only support the simple one with Jenkinsfile inside.'`,
-		Example: "ks pip create --ws simple --project test --template simple --name simple",
+The user-defined templates are loaded from the template directory and the ConfigMaps which have the label
+devops.kubesphere.io/pipeline-template=true, each item of the ConfigMap data is a template.
+KubeSphere supports multiple types Pipeline. The multi-branch Pipeline could be created from git, GitHub, GitLab,
+Gitea and Bitbucket Server, the credential given by --credential-id must exist in the DevOps project.`,
+		Example: `ks pip create --ws simple --project test --template simple --name simple
+ks pip create --ws simple --project test --name simple --template maven --var image=maven:3
+ks pip create --ws simple --project test --name simple --jenkinsfile ./Jenkinsfile
+ks pip create --ws simple --project test --name simple --from-git https://github.com/devops-ws/learn-pipeline-java --ref master
+ks pip create --ws simple --project test --name simple --type multi-branch-pipeline --scm-type github \
+	--repo devops-ws/learn-pipeline-java --credential-id github-token --discover-branches all`,
 		PreRunE: opt.preRunE,
 		RunE:    opt.runE,
 	}
@@ -41,20 +52,68 @@ KubeSphere supports multiple types Pipeline. Currently, this CLI only support th
 	flags.StringVarP(&opt.Name, "name", "", "",
 		"The name of the Pipeline")
 	flags.StringVarP(&opt.Jenkinsfile, "jenkinsfile", "", "",
-		"The Jenkinsfile of the Pipeline")
+		"The Jenkinsfile of the Pipeline, it could be the path of a local file or the content")
+	flags.StringVarP(&opt.GitURL, "from-git", "", "",
+		"The git repository to read the Jenkinsfile from")
+	flags.StringVarP(&opt.GitPath, "path", "", "Jenkinsfile",
+		"The path of the Jenkinsfile in the git repository")
+	flags.StringVarP(&opt.GitRef, "ref", "", "",
+		"The branch or tag of the git repository, use the default branch if it's empty")
+	flags.IntVarP(&opt.DaysToKeep, "discarder-days", "", 7,
+		"Days to keep the PipelineRuns")
+	flags.IntVarP(&opt.NumToKeep, "discarder-count", "", 10,
+		"Maximum number of PipelineRuns to keep")
+	flags.BoolVarP(&opt.Concurrent, "concurrent", "", false,
+		"Allow running the Pipeline concurrently")
+	flags.StringVarP(&opt.Description, "description", "", "",
+		"The description of the Pipeline")
 	flags.StringVarP(&opt.Template, "template", "", "",
-		"Template of Jenkinsfile include: java, go. This option will override the option --jenkinsfile")
+		"Template of Jenkinsfile include: java, go, or a user-defined one. This option will override the option --jenkinsfile")
+	flags.StringVarP(&opt.TemplateDir, "template-dir", "", tpl.GetTemplateDir(),
+		"The directory of the user-defined templates")
+	flags.StringToStringVarP(&opt.TemplateVariables, "var", "", nil,
+		"The variables of the user-defined template, for example: --var image=maven")
 	flags.StringVarP(&opt.Type, "type", "", "pipeline",
 		"The type of pipeline, could be pipeline, multi_branch_pipeline")
 	flags.StringVarP(&opt.SCMType, "scm-type", "", "",
-		"The SCM type of pipeline, could be gitlab, github")
+		"The SCM type of pipeline, could be "+strings.Join(option.GetSCMTypes(), ", "))
+	flags.StringVarP(&opt.SCMRepo, "repo", "", "",
+		"The repository of the multi-branch Pipeline, it's the URL for git, or owner/repo for the others")
+	flags.StringVarP(&opt.SCMOwner, "owner", "", "",
+		"The owner of the repository, take it from --repo if it's empty")
+	flags.StringVarP(&opt.SCMServer, "server", "", "",
+		"The server of GitLab, Gitea, Bitbucket Server or GitHub Enterprise, such as: https://gitlab.com")
+	flags.StringVarP(&opt.CredentialID, "credential-id", "", "",
+		"The ID of the credential to access the repository, it must exist in the DevOps project")
+	flags.StringVarP(&opt.ScriptPath, "script-path", "", "Jenkinsfile",
+		"The path of the Jenkinsfile in the repository")
+	flags.StringVarP(&opt.DiscoverBranches, "discover-branches", "", "exclude-pr",
+		"The strategy of discovering branches, could be "+strings.Join(option.GetDiscoverBranchesStrategies(), ", "))
+	flags.StringVarP(&opt.DiscoverPRFromOrigin, "discover-pr-from-origin", "", "head",
+		"The strategy of discovering pull requests from the origin, could be "+
+			strings.Join(option.GetDiscoverPRStrategies(), ", "))
+	flags.StringVarP(&opt.DiscoverPRFromForks, "discover-pr-from-forks", "", "head",
+		"The strategy of discovering pull requests from forks, could be "+
+			strings.Join(option.GetDiscoverPRStrategies(), ", "))
+	flags.StringVarP(&opt.DiscoverForkTrust, "discover-fork-trust", "", "everyone",
+		"The trust of the pull requests from forks, could be "+strings.Join(option.GetDiscoverForkTrusts(), ", "))
+	flags.BoolVarP(&opt.DiscoverTags, "discover-tags", "", true,
+		"Discover the tags of the repository")
 	flags.BoolVarP(&opt.Batch, "batch", "b", false, "Create pipeline as batch mode")
 	flags.BoolVarP(&opt.SkipCheck, "skip-check", "", false, "Skip the resources check")
+	flags.BoolVarP(&opt.skipLint, "skip-lint", "", false, "Skip checking the Jenkinsfile")
 
-	_ = cmd.RegisterFlagCompletionFunc("template",
-		common.ArrayCompletion(tpl.GetAllTemplates()...))
+	_ = cmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) (
+		[]string, cobra.ShellCompDirective) {
+		_ = opt.LoadTemplates()
+		return opt.GetTemplateNames(), cobra.ShellCompDirectiveNoFileComp
+	})
 	_ = cmd.RegisterFlagCompletionFunc("type", common.ArrayCompletion("pipeline", "multi-branch-pipeline"))
-	_ = cmd.RegisterFlagCompletionFunc("scm-type", common.ArrayCompletion("gitlab", "github", "git"))
+	_ = cmd.RegisterFlagCompletionFunc("scm-type", common.ArrayCompletion(option.GetSCMTypes()...))
+	_ = cmd.RegisterFlagCompletionFunc("discover-branches", common.ArrayCompletion(option.GetDiscoverBranchesStrategies()...))
+	_ = cmd.RegisterFlagCompletionFunc("discover-pr-from-origin", common.ArrayCompletion(option.GetDiscoverPRStrategies()...))
+	_ = cmd.RegisterFlagCompletionFunc("discover-pr-from-forks", common.ArrayCompletion(option.GetDiscoverPRStrategies()...))
+	_ = cmd.RegisterFlagCompletionFunc("discover-fork-trust", common.ArrayCompletion(option.GetDiscoverForkTrusts()...))
 
 	// TODO needs to find a better way to add the completion support
 	// it takes long time (around 1min) to initialize the whole command if the k8s config is not reachable
@@ -79,10 +138,20 @@ func (o *innerPipelineCreateOption) preRunE(cmd *cobra.Command, args []string) (
 		return
 	}
 
+	if err = o.LoadJenkinsfile(); err != nil {
+		return
+	}
+
 	if err = o.ParseTemplate(); err != nil {
 		return
 	}
 
+	if !o.skipLint && o.Type == option.NoScmPipelineType && o.Jenkinsfile != "" {
+		if err = lintJenkinsfile(cmd, o.Jenkinsfile); err != nil {
+			return
+		}
+	}
+
 	if o.Name == "" && len(args) > 0 {
 		o.Name = args[0]
 	}
@@ -142,7 +142,7 @@ func (o *dashboardOption) createPipelineList() (listView tview.Primitive) {
 			row, col := table.GetSelection()
 			cell := table.GetCell(row, col)
 			pipeline := cell.Text
-			err := run.triggerPipeline(o.namespace, pipeline, nil)
+			_, err := run.triggerPipeline(o.namespace, pipeline, nil)
 			if err != nil {
 				log.Errorf("failed to triggre pipeline, error %v", err)
 			}
@@ -154,7 +154,7 @@ func (o *dashboardOption) createPipelineList() (listView tview.Primitive) {
 			row, col := table.GetSelection()
 			cell := table.GetCell(row, col)
 			pipeline := cell.Text
-			err := run.triggerPipeline(o.namespace, pipeline, nil)
+			_, err := run.triggerPipeline(o.namespace, pipeline, nil)
 			if err != nil {
 				log.Errorf("failed to triggre pipeline, error %v", err)
 			}
@@ -218,6 +218,12 @@ func (o *dashboardOption) resourceYAMLView(groupVer schema.GroupVersionResource,
 }
 
 func (o *dashboardOption) pipelineCreationForm() {
+	templateOpt := &option.PipelineCreateOption{
+		TemplateDir: tpl.GetTemplateDir(),
+		Client:      o.client,
+	}
+	_ = templateOpt.LoadTemplates()
+
 	form := tview.NewForm()
 	form.AddButton("OK", func() {
 		nameItem := form.GetFormItemByLabel("Name")
@@ -228,14 +234,15 @@ func (o *dashboardOption) pipelineCreationForm() {
 			_, templateName := templateField.GetCurrentOption()
 
 			opt := &option.PipelineCreateOption{
-				Name:      nameField.GetText(),
-				Project:   o.namespaceProjectMap[o.namespace],
-				Template:  templateName,
-				Workspace: o.namespaceWorkspaceMap[o.namespace],
-				Batch:     true,
-				Type:      "pipeline",
-				Client:    o.client,
-				SkipCheck: true,
+				Name:        nameField.GetText(),
+				Project:     o.namespaceProjectMap[o.namespace],
+				Template:    templateName,
+				TemplateDir: templateOpt.TemplateDir,
+				Workspace:   o.namespaceWorkspaceMap[o.namespace],
+				Batch:       true,
+				Type:        "pipeline",
+				Client:      o.client,
+				SkipCheck:   true,
 			}
 			_ = opt.ParseTemplate()
 			err := opt.CreatePipeline() // need to find a way to show the errors
@@ -249,7 +256,7 @@ func (o *dashboardOption) pipelineCreationForm() {
 		AddButton("Cancel", func() {
 			o.stack.Pop()
 		})
-	form.AddDropDown("Template", tpl.GetAllTemplates(), 0, func(option string, optionIndex int) {
+	form.AddDropDown("Template", templateOpt.GetTemplateNames(), 0, func(option string, optionIndex int) {
 		if formItem := form.GetFormItemByLabel("Name"); formItem != nil {
 			inputField := formItem.(*tview.InputField)
 			inputField.SetText(strings.ToLower(fmt.Sprintf("%s-%s", option, randomdata.SillyName())))
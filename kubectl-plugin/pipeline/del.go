@@ -3,31 +3,237 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/client-go/dynamic"
 )
 
 // newDelPipelineCmd returns a command to delete pipelines
 func newDelPipelineCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineDeleteOption{
+		client: client,
+	}
 	cmd = &cobra.Command{
 		Use:     "delete",
 		Aliases: []string{"del", "remove", "rm"},
 		Short:   "Delete a specific Pipeline of KubeSphere DevOps",
-		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			var pips []string
-			var ns string
-			if ns, pips, err = getPipelinesWithConfirm(client, args); err == nil {
-				for _, pip := range pips {
-					fmt.Println(pip)
-					if err = client.Resource(types.GetPipelineSchema()).Namespace(ns).Delete(context.TODO(), pip, metav1.DeleteOptions{}); err != nil {
-						break
-					}
+		Long: `Delete a specific Pipeline of KubeSphere DevOps
+The Pipelines are selected interactively unless the names or any of --all, --selector, --older-than and --no-runs-since are given.
+The deletion needs to be confirmed once after the summary is printed, unless --yes is given.`,
+		Example: `ks pip delete devops-ns pipeline-a pipeline-b --yes
+ks pip delete devops-ns --selector app=demo --cascade --dry-run
+ks pip delete devops-ns --all --no-runs-since 720h --yes`,
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opt.all, "all", "", false,
+		"Delete all the Pipelines in the namespace")
+	flags.StringVarP(&opt.selector, "selector", "l", "",
+		"Delete the Pipelines which match the label selector, such as: app=demo")
+	flags.DurationVarP(&opt.olderThan, "older-than", "", 0,
+		"Only delete the Pipelines which were created before this duration, such as: 720h")
+	flags.DurationVarP(&opt.noRunsSince, "no-runs-since", "", 0,
+		"Only delete the Pipelines which have no PipelineRuns created in this duration, such as: 720h")
+	flags.BoolVarP(&opt.yes, "yes", "y", false,
+		"Delete the Pipelines without the confirmation")
+	flags.BoolVarP(&opt.cascade, "cascade", "", false,
+		"Delete the PipelineRuns of the Pipelines as well")
+	flags.BoolVarP(&opt.dryRun, "dry-run", "", false,
+		"Only print the Pipelines which would be deleted")
+	return
+}
+
+type pipelineDeleteOption struct {
+	all         bool
+	selector    string
+	olderThan   time.Duration
+	noRunsSince time.Duration
+	yes         bool
+	cascade     bool
+	dryRun      bool
+
+	// inner fields
+	client    dynamic.Interface
+	namespace string
+	names     []string
+}
+
+// pipelineToDelete is a Pipeline which is going to be deleted
+type pipelineToDelete struct {
+	name         string
+	creationTime time.Time
+	// lastRunTime is zero if there is no PipelineRun
+	lastRunTime time.Time
+	runs        []string
+}
+
+func (o *pipelineDeleteOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	if len(args) > 1 {
+		if o.all || o.selector != "" {
+			err = fmt.Errorf("the Pipeline names cannot be used together with --all or --selector")
+			return
+		}
+		o.names = args[1:]
+	}
+	o.namespace, err = getNamespace(o.client, args)
+	return
+}
+
+func (o *pipelineDeleteOption) runE(cmd *cobra.Command, args []string) (err error) {
+	ctx := context.TODO()
+
+	var pips []pipelineToDelete
+	if pips, err = o.getPipelinesToDelete(ctx); err != nil {
+		return
+	}
+	if len(pips) == 0 {
+		cmd.Printf("no Pipelines matched in namespace '%s'\n", o.namespace)
+		return
+	}
+
+	if err = o.printSummary(cmd, pips); err != nil || o.dryRun {
+		return
+	}
+	if !o.yes {
+		var ok bool
+		if err = survey.AskOne(&survey.Confirm{Message: "Delete them?"}, &ok); err != nil || !ok {
+			if err == nil {
+				cmd.Println("aborted")
+			}
+			return
+		}
+	}
+
+	for _, pip := range pips {
+		if o.cascade {
+			for _, run := range pip.runs {
+				if err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(o.namespace).Delete(ctx, run,
+					metav1.DeleteOptions{}); err != nil {
+					err = fmt.Errorf("failed to delete PipelineRun %s/%s, error: %v", o.namespace, run, err)
+					return
 				}
 			}
+		}
+		if err = o.client.Resource(types.GetPipelineSchema()).Namespace(o.namespace).Delete(ctx, pip.name,
+			metav1.DeleteOptions{}); err != nil {
+			err = fmt.Errorf("failed to delete Pipeline %s/%s, error: %v", o.namespace, pip.name, err)
+			return
+		}
+		cmd.Printf("pipeline %s/%s deleted\n", o.namespace, pip.name)
+	}
+	return
+}
+
+// getPipelinesToDelete returns the given Pipelines, or the ones which match the filters.
+// It asks for choosing the Pipelines if there is neither a name nor a filter, the deletion is confirmed later.
+func (o *pipelineDeleteOption) getPipelinesToDelete(ctx context.Context) (pips []pipelineToDelete, err error) {
+	var items []unstructured.Unstructured
+	switch {
+	case len(o.names) > 0:
+		items, err = o.getPipelinesByNames(ctx, o.names)
+	case o.all || o.selector != "" || o.olderThan > 0 || o.noRunsSince > 0:
+		var list *unstructured.UnstructuredList
+		if list, err = o.client.Resource(types.GetPipelineSchema()).Namespace(o.namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: o.selector,
+		}); err == nil {
+			items = list.Items
+		}
+	default:
+		var allNames, names []string
+		if _, allNames, err = getPipelines(o.client, []string{o.namespace}); err != nil {
 			return
-		},
+		}
+		if err = survey.AskOne(&survey.MultiSelect{
+			Message: "Please select the Pipelines to delete:",
+			Options: allNames,
+		}, &names); err == nil {
+			items, err = o.getPipelinesByNames(ctx, names)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		pip := pipelineToDelete{
+			name:         item.GetName(),
+			creationTime: item.GetCreationTimestamp().Time,
+		}
+		if o.olderThan > 0 && pip.creationTime.After(now.Add(-o.olderThan)) {
+			continue
+		}
+
+		var runs []unstructured.Unstructured
+		if runs, err = getPipelineRunList(ctx, o.client, o.namespace, pip.name); err != nil {
+			return
+		}
+		if len(runs) > 0 {
+			pip.lastRunTime = runs[0].GetCreationTimestamp().Time
+		}
+		if o.noRunsSince > 0 && pip.lastRunTime.After(now.Add(-o.noRunsSince)) {
+			continue
+		}
+		for _, run := range runs {
+			pip.runs = append(pip.runs, run.GetName())
+		}
+		pips = append(pips, pip)
+	}
+	return
+}
+
+func (o *pipelineDeleteOption) getPipelinesByNames(ctx context.Context, names []string) (items []unstructured.Unstructured, err error) {
+	for _, name := range names {
+		var item *unstructured.Unstructured
+		if item, err = o.client.Resource(types.GetPipelineSchema()).Namespace(o.namespace).Get(ctx, name,
+			metav1.GetOptions{}); err != nil {
+			err = fmt.Errorf("failed to get Pipeline %s/%s, error: %v", o.namespace, name, err)
+			return
+		}
+		items = append(items, *item)
+	}
+	return
+}
+
+func (o *pipelineDeleteOption) printSummary(cmd *cobra.Command, pips []pipelineToDelete) (err error) {
+	now := time.Now()
+	rows := make([][]string, 0, len(pips))
+	var runCount int
+	for _, pip := range pips {
+		lastRun := "<none>"
+		if !pip.lastRunTime.IsZero() {
+			lastRun = duration.HumanDuration(now.Sub(pip.lastRunTime))
+		}
+		rows = append(rows, []string{pip.name, duration.HumanDuration(now.Sub(pip.creationTime)), lastRun,
+			strconv.Itoa(len(pip.runs))})
+		runCount += len(pip.runs)
+	}
+	if err = common.PrintTable(cmd.OutOrStdout(), []string{"NAME", "AGE", "LAST RUN", "RUNS"}, rows); err != nil {
+		return
+	}
+
+	summary := fmt.Sprintf("%d Pipelines in namespace '%s'", len(pips), o.namespace)
+	if o.cascade {
+		summary += fmt.Sprintf(" and %d PipelineRuns", runCount)
+	}
+	if o.dryRun {
+		cmd.Printf("%s would be deleted, nothing was changed because of the dry run mode\n", summary)
+	} else {
+		cmd.Printf("%s will be deleted\n", summary)
 	}
 	return
 }
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+func TestDelPipeline(t *testing.T) {
+	now := time.Now()
+	old := newFakePipeline("ns", "old", "")
+	old.SetCreationTimestamp(metav1.NewTime(now.Add(-48 * time.Hour)))
+	old.SetLabels(map[string]string{"app": "demo"})
+	active := newFakePipeline("ns", "active", "")
+	active.SetCreationTimestamp(metav1.NewTime(now.Add(-48 * time.Hour)))
+	active.SetLabels(map[string]string{"app": "demo"})
+	fresh := newFakePipeline("ns", "fresh", "")
+	fresh.SetCreationTimestamp(metav1.NewTime(now.Add(-time.Hour)))
+
+	client := newFakeDynamicClient(old, active, fresh,
+		newFakePipelineRun("old-1", "old", now.Add(-40*time.Hour)),
+		newFakePipelineRun("active-1", "active", now.Add(-time.Hour)))
+
+	// preview the Pipelines which match the selector
+	buf := bytes.NewBuffer(nil)
+	cmd := newDelPipelineCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "--selector", "app=demo", "--cascade", "--dry-run"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "2 Pipelines in namespace 'ns' and 2 PipelineRuns would be deleted")
+	assert.ElementsMatch(t, []string{"old", "active", "fresh"}, getFakePipelineNames(t, client))
+
+	// the filters work together
+	buf.Reset()
+	cmd = newDelPipelineCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "--all", "--older-than", "24h", "--no-runs-since", "24h", "--cascade", "--yes"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "pipeline ns/old deleted")
+	assert.ElementsMatch(t, []string{"active", "fresh"}, getFakePipelineNames(t, client))
+	runs, err := client.Resource(types.GetPipelineRunSchema()).Namespace("ns").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, runs.Items, 1)
+
+	// the given names are deleted without selecting
+	cmd = newDelPipelineCmd(client)
+	cmd.SetOut(bytes.NewBuffer(nil))
+	cmd.SetArgs([]string{"ns", "fresh", "--yes"})
+	assert.Nil(t, cmd.Execute())
+	assert.ElementsMatch(t, []string{"active"}, getFakePipelineNames(t, client))
+
+	buf.Reset()
+	cmd = newDelPipelineCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "--selector", "app=fake"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "no Pipelines matched")
+
+	cmd = newDelPipelineCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"ns", "active", "--all"})
+	assert.NotNil(t, cmd.Execute())
+
+	cmd = newDelPipelineCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"ns", "fake", "--yes"})
+	assert.NotNil(t, cmd.Execute())
+}
+
+func getFakePipelineNames(t *testing.T, client dynamic.Interface) (names []string) {
+	list, err := client.Resource(types.GetPipelineSchema()).Namespace("ns").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return
+}
@@ -0,0 +1,259 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	devopsclient "github.com/kubesphere/ks-devops-client-go/client"
+	"github.com/kubesphere/ks-devops-client-go/client/dev_ops_pipeline"
+	"github.com/kubesphere/ks-devops-client-go/models"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// devopsAPIOption holds the connection options of the devops apiserver
+type devopsAPIOption struct {
+	devopsAPIHost    string
+	devopsAPISchemes []string
+	// devopsAPIQPS limits the requests to the devops apiserver, a non-positive value means no limit
+	devopsAPIQPS   float32
+	devopsAPIBurst int
+
+	// inner fields
+	devopsClient *devopsclient.KubeSphereDevOps
+}
+
+func (o *devopsAPIOption) addDevOpsAPIFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.devopsAPIHost, "devops-api-host", "", "devops-apiserver.kubesphere-devops-system.svc:9090",
+		"The devops apiserver address")
+	flags.StringArrayVarP(&o.devopsAPISchemes, "devops-api-schemes", "", []string{"http"},
+		"The schemes to connect to devops apiserver")
+}
+
+func (o *devopsAPIOption) initDevopsClient() error {
+	transport := httptransport.New(o.devopsAPIHost, devopsclient.DefaultBasePath, o.devopsAPISchemes)
+	if o.devopsAPIQPS > 0 {
+		burst := o.devopsAPIBurst
+		if burst < 1 {
+			burst = 1
+		}
+		transport.Transport = &rateLimitedRoundTripper{
+			limiter: flowcontrol.NewTokenBucketRateLimiter(o.devopsAPIQPS, burst),
+			next:    transport.Transport,
+		}
+	}
+	o.devopsClient = devopsclient.New(transport, strfmt.Default)
+	return nil
+}
+
+// rateLimitedRoundTripper waits for the rate limiter before sending a request
+type rateLimitedRoundTripper struct {
+	limiter flowcontrol.RateLimiter
+	next    http.RoundTripper
+}
+
+// RoundTrip sends the request once the rate limiter allows it
+func (r *rateLimitedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := r.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return r.next.RoundTrip(req)
+}
+
+// getPipelineBranches returns the names of the branches and pull requests which exist in the SCM of a multi-branch Pipeline
+func (o *devopsAPIOption) getPipelineBranches(ctx context.Context, ns, pipeline string) (branches map[string]bool, err error) {
+	branches = map[string]bool{}
+	limit := 100
+	for page := 1; ; page++ {
+		pageQuery, limitQuery := strconv.Itoa(page), strconv.Itoa(limit)
+		var result *dev_ops_pipeline.GetBranchesOK
+		if result, err = o.devopsClient.DevOpsPipeline.GetBranches(&dev_ops_pipeline.GetBranchesParams{
+			Namespace: ns,
+			Pipeline:  pipeline,
+			Page:      &pageQuery,
+			Limit:     &limitQuery,
+			Context:   ctx,
+		}); err != nil {
+			return
+		}
+
+		var items []models.APIListResultItems
+		if payload := result.GetPayload(); payload != nil {
+			items = payload.Items
+		}
+		for _, item := range items {
+			branch, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, key := range []string{"name", "rawName"} {
+				if name, _ := branch[key].(string); name != "" {
+					if unescaped, unescapeErr := url.PathUnescape(name); unescapeErr == nil {
+						name = unescaped
+					}
+					branches[name] = true
+				}
+			}
+		}
+		if len(items) < limit {
+			return
+		}
+	}
+}
+
+// stopRun stops a Jenkins run, the run of a multi-branch Pipeline will be stopped when the branch is not empty
+func (o *devopsAPIOption) stopRun(ctx context.Context, ns, pipeline, branch, runID string) (err error) {
+	if branch != "" {
+		_, err = o.devopsClient.DevOpsPipeline.StopBranchPipeline(&dev_ops_pipeline.StopBranchPipelineParams{
+			Blocking:      aws.String("true"),
+			Body:          []int64{},
+			Branch:        branch,
+			Devops:        ns,
+			Pipeline:      pipeline,
+			Run:           runID,
+			TimeOutInSecs: aws.String("10"),
+			Context:       ctx,
+		})
+	} else {
+		_, err = o.devopsClient.DevOpsPipeline.StopPipeline(&dev_ops_pipeline.StopPipelineParams{
+			Blocking:      aws.String("true"),
+			Body:          []int64{},
+			Devops:        ns,
+			Pipeline:      pipeline,
+			Run:           runID,
+			TimeOutInSecs: aws.String("10"),
+			Context:       ctx,
+		})
+	}
+	return
+}
+
+// runLogChunk represents a piece of the progressive log of a Jenkins run
+type runLogChunk struct {
+	// next is the offset of the next piece of the log
+	next int64
+	// more indicates if there is more data of the log
+	more bool
+}
+
+// writeRunLog writes the log of a Jenkins run which starts from the given offset into the writer.
+// The log of a multi-branch Pipeline will be fetched when the branch is not empty.
+func (o *devopsAPIOption) writeRunLog(ctx context.Context, w io.Writer, ns, pipeline, branch, runID string,
+	start int64) (chunk *runLogChunk, err error) {
+	chunk = &runLogChunk{next: start}
+	startQuery := strconv.FormatInt(start, 10)
+	readOpt := logResponseReader(w, chunk)
+
+	if branch != "" {
+		_, err = o.devopsClient.DevOpsPipeline.GetBranchRunLog(&dev_ops_pipeline.GetBranchRunLogParams{
+			Branch:   branch,
+			Devops:   ns,
+			Pipeline: pipeline,
+			Run:      runID,
+			Start:    &startQuery,
+			Context:  ctx,
+		}, readOpt)
+	} else {
+		_, err = o.devopsClient.DevOpsPipeline.GetRunLog(&dev_ops_pipeline.GetRunLogParams{
+			Devops:   ns,
+			Pipeline: pipeline,
+			Run:      runID,
+			Start:    &startQuery,
+			Context:  ctx,
+		}, readOpt)
+	}
+	return
+}
+
+// getRunArtifacts returns the downloadable artifacts of a Jenkins run, such as the archived test reports.
+// The artifacts of a multi-branch Pipeline will be listed when the branch is not empty.
+func (o *devopsAPIOption) getRunArtifacts(ctx context.Context, ns, pipeline, branch, runID string) (
+	artifacts []*models.DevopsArtifacts, err error) {
+	var items []*models.DevopsArtifacts
+	if branch != "" {
+		var resp *dev_ops_pipeline.GetBranchArtifactsOK
+		if resp, err = o.devopsClient.DevOpsPipeline.GetBranchArtifacts(&dev_ops_pipeline.GetBranchArtifactsParams{
+			Branch:   branch,
+			Devops:   ns,
+			Pipeline: pipeline,
+			Run:      runID,
+			Context:  ctx,
+		}); err == nil {
+			items = resp.GetPayload()
+		}
+	} else {
+		var resp *dev_ops_pipeline.GetArtifactsOK
+		if resp, err = o.devopsClient.DevOpsPipeline.GetArtifacts(&dev_ops_pipeline.GetArtifactsParams{
+			Devops:   ns,
+			Pipeline: pipeline,
+			Run:      runID,
+			Context:  ctx,
+		}); err == nil {
+			items = resp.GetPayload()
+		}
+	}
+
+	for _, item := range items {
+		if item != nil && item.Downloadable && item.Path != "" {
+			artifacts = append(artifacts, item)
+		}
+	}
+	return
+}
+
+// downloadArtifact writes an artifact of a PipelineRun into the writer, the filename is the path of the artifact
+func (o *devopsAPIOption) downloadArtifact(ctx context.Context, w io.Writer, ns, pipelineRun, filename string) (err error) {
+	_, err = o.devopsClient.DevOpsPipeline.DownloadArtifact(&dev_ops_pipeline.DownloadArtifactParams{
+		Filename:    &filename,
+		Namespace:   ns,
+		Pipelinerun: pipelineRun,
+		Context:     ctx,
+	}, bodyResponseReader(w))
+	return
+}
+
+// bodyResponseReader copies the body into the writer, the generated client drops it
+func bodyResponseReader(w io.Writer) dev_ops_pipeline.ClientOption {
+	return func(op *runtime.ClientOperation) {
+		reader := op.Reader
+		op.Reader = runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if resp.Code() == http.StatusOK {
+				if _, err := io.Copy(w, resp.Body()); err != nil {
+					return nil, err
+				}
+			}
+			return reader.ReadResponse(resp, consumer)
+		})
+	}
+}
+
+// logResponseReader copies the plain text body into the writer, the generated client drops it
+func logResponseReader(w io.Writer, chunk *runLogChunk) dev_ops_pipeline.ClientOption {
+	return func(op *runtime.ClientOperation) {
+		reader := op.Reader
+		op.Reader = runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if resp.Code() == http.StatusOK {
+				written, err := io.Copy(w, resp.Body())
+				if err != nil {
+					return nil, err
+				}
+
+				// Jenkins returns the size of the log via the progressive text API headers
+				if size, parseErr := strconv.ParseInt(resp.GetHeader("X-Text-Size"), 10, 64); parseErr == nil {
+					chunk.next = size
+				} else {
+					chunk.next += written
+				}
+				chunk.more = resp.GetHeader("X-More-Data") == "true"
+			}
+			return reader.ReadResponse(resp, consumer)
+		})
+	}
+}
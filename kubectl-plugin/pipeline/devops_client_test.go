@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRunLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/runs/1/log":
+			assert.Equal(t, "0", r.URL.Query().Get("start"))
+			w.Header().Set("X-Text-Size", "20")
+			w.Header().Set("X-More-Data", "true")
+			_, _ = w.Write([]byte("hello"))
+		case "/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/branches/master/runs/1/log":
+			assert.Equal(t, "5", r.URL.Query().Get("start"))
+			_, _ = w.Write([]byte("world"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	opt := &devopsAPIOption{
+		devopsAPIHost:    strings.TrimPrefix(server.URL, "http://"),
+		devopsAPISchemes: []string{"http"},
+	}
+	assert.Nil(t, opt.initDevopsClient())
+
+	buf := bytes.NewBuffer(nil)
+	chunk, err := opt.writeRunLog(context.TODO(), buf, "ns", "pip", "", "1", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", buf.String())
+	assert.Equal(t, int64(20), chunk.next)
+	assert.True(t, chunk.more)
+
+	buf.Reset()
+	chunk, err = opt.writeRunLog(context.TODO(), buf, "ns", "pip", "master", "1", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, "world", buf.String())
+	assert.Equal(t, int64(10), chunk.next)
+	assert.False(t, chunk.more)
+
+	_, err = opt.writeRunLog(context.TODO(), buf, "ns", "fake", "", "1", 0)
+	assert.NotNil(t, err)
+}
+
+func TestRateLimitedDevOpsClient(t *testing.T) {
+	var count int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		_, _ = w.Write([]byte("log"))
+	}))
+	defer server.Close()
+
+	opt := &devopsAPIOption{
+		devopsAPIHost:    strings.TrimPrefix(server.URL, "http://"),
+		devopsAPISchemes: []string{"http"},
+		devopsAPIQPS:     0.1,
+		devopsAPIBurst:   1,
+	}
+	assert.Nil(t, opt.initDevopsClient())
+
+	// the first request is allowed by the burst, the second one has to wait
+	_, err := opt.writeRunLog(context.TODO(), bytes.NewBuffer(nil), "ns", "pip", "", "1", 0)
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	_, err = opt.writeRunLog(ctx, bytes.NewBuffer(nil), "ns", "pip", "", "1", 0)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, count)
+}
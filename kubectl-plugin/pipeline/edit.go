@@ -10,23 +10,38 @@ import (
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"os"
 	"sigs.k8s.io/yaml"
+	"strings"
 )
 
 // newPipelineEditCmd returns a command to edit the pipeline
 func newPipelineEditCmd(client dynamic.Interface) (cmd *cobra.Command) {
 	ctx := context.TODO()
+	var skipLint, onlyJenkinsfile bool
 	cmd = &cobra.Command{
 		Use:     "edit",
 		Aliases: []string{"e"},
 		Short:   "Edit the target pipeline",
+		Long: `Edit the target pipeline
+Only the Jenkinsfile will be opened as a Groovy file when the flag --jenkinsfile is given. The changes will be shown
+as a unified diff before applying. A three-way merge is offered if the Pipeline was changed by others in the meantime.`,
+		Example: `ks pip edit devops-ns my-pipeline
+ks pip edit devops-ns my-pipeline --jenkinsfile`,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			var pips []string
 			var ns string
 			if ns, pips, err = getPipelinesWithConfirm(client, args); err == nil {
 				for _, pip := range pips {
+					if onlyJenkinsfile {
+						if err = editJenkinsfile(cmd, client, ns, pip, skipLint); err != nil {
+							return
+						}
+						continue
+					}
+
 					var rawPip *unstructured.Unstructured
 					var data []byte
 					buf := bytes.NewBuffer(data)
@@ -58,16 +73,157 @@ func newPipelineEditCmd(client dynamic.Interface) (cmd *cobra.Command) {
 						AppendDefault: true,
 					}
 
-					err = survey.AskOne(prompt, &content, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
+					if err = survey.AskOne(prompt, &content, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr)); err != nil {
+						err = fmt.Errorf("aborted editing pipeline %s/%s, error: %v", ns, pip, err)
+						return
+					}
 
-					if err = yaml.Unmarshal([]byte(content), rawPip); err == nil {
-						_, err = client.Resource(types.GetPipelineSchema()).Namespace(ns).Update(context.TODO(), rawPip, metav1.UpdateOptions{})
+					if err = yaml.Unmarshal([]byte(content), rawPip); err != nil {
+						return
+					}
+
+					if text, ok, _ := unstructured.NestedString(rawPip.Object, "spec", "pipeline", "jenkinsfile"); ok && !skipLint {
+						if err = lintJenkinsfile(cmd, text); err != nil {
+							return
+						}
+					}
+					if _, err = client.Resource(types.GetPipelineSchema()).Namespace(ns).Update(context.TODO(), rawPip, metav1.UpdateOptions{}); err != nil {
+						return
 					}
 				}
 			}
 			return
 		},
 	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&skipLint, "skip-lint", "", false, "Skip checking the Jenkinsfile")
+	flags.BoolVarP(&onlyJenkinsfile, "jenkinsfile", "", false, "Edit only the Jenkinsfile of the Pipeline")
+	return
+}
+
+// editJenkinsfile edits the Jenkinsfile of a Pipeline, and writes only that field back
+func editJenkinsfile(cmd *cobra.Command, client dynamic.Interface, ns, name string, skipLint bool) (err error) {
+	ctx := context.TODO()
+	var rawPip *unstructured.Unstructured
+	if rawPip, err = client.Resource(types.GetPipelineSchema()).Namespace(ns).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		err = fmt.Errorf("cannot get pipeline, error: %v", err)
+		return
+	}
+
+	base, found, _ := unstructured.NestedString(rawPip.Object, "spec", "pipeline", "jenkinsfile")
+	if !found {
+		err = fmt.Errorf("no Jenkinsfile found in Pipeline %s/%s, only the Pipeline of type pipeline has it", ns, name)
+		return
+	}
+
+	var content string
+	if content, err = editText(fmt.Sprintf("Edit the Jenkinsfile of pipeline %s/%s", ns, name), base); err != nil {
+		return
+	}
+	if strings.TrimRight(content, "\n") == strings.TrimRight(base, "\n") {
+		cmd.Printf("no changes of the Jenkinsfile of pipeline %s/%s\n", ns, name)
+		return
+	}
+
+	if !skipLint {
+		if err = lintJenkinsfile(cmd, content); err != nil {
+			return
+		}
+	}
+
+	cmd.Print(unifiedDiff("Jenkinsfile", base, content))
+	var ok bool
+	if err = survey.AskOne(&survey.Confirm{Message: "Apply the changes?"}, &ok); err != nil || !ok {
+		return
+	}
+
+	var latest *unstructured.Unstructured
+	if latest, err = client.Resource(types.GetPipelineSchema()).Namespace(ns).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return
+	}
+	if latest.GetResourceVersion() != rawPip.GetResourceVersion() {
+		theirs, _, _ := unstructured.NestedString(latest.Object, "spec", "pipeline", "jenkinsfile")
+		if theirs != base {
+			if content, err = mergeJenkinsfile(cmd, ns, name, base, content, theirs); err != nil {
+				return
+			}
+			// the merged or resolved Jenkinsfile needs to be checked again
+			if !skipLint {
+				if err = lintJenkinsfile(cmd, content); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	// write only the Jenkinsfile, the resourceVersion makes sure there is no more changes in the meantime
+	var patch []byte
+	if patch, err = json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": latest.GetResourceVersion(),
+		},
+		"spec": map[string]interface{}{
+			"pipeline": map[string]interface{}{
+				"jenkinsfile": content,
+			},
+		},
+	}); err != nil {
+		return
+	}
+	if _, err = client.Resource(types.GetPipelineSchema()).Namespace(ns).Patch(ctx, name, k8stypes.MergePatchType,
+		patch, metav1.PatchOptions{}); err != nil {
+		err = fmt.Errorf("failed to update the Jenkinsfile of pipeline %s/%s, error: %v", ns, name, err)
+	}
+	return
+}
+
+// mergeJenkinsfile merges the Jenkinsfile which was changed by others in the meantime
+func mergeJenkinsfile(cmd *cobra.Command, ns, name, base, ours, theirs string) (result string, err error) {
+	cmd.Printf("the Jenkinsfile of pipeline %s/%s was changed by others in the meantime:\n", ns, name)
+	cmd.Print(unifiedDiff("Jenkinsfile", base, theirs))
+
+	var ok bool
+	if err = survey.AskOne(&survey.Confirm{Message: "Merge the changes?"}, &ok); err != nil {
+		return
+	}
+	if !ok {
+		err = fmt.Errorf("the Jenkinsfile of pipeline %s/%s was changed by others", ns, name)
+		return
+	}
+
+	var conflict bool
+	if result, conflict = mergeText(base, ours, theirs); conflict {
+		for conflict {
+			if result, err = editText("Resolve the conflicts of the Jenkinsfile", result); err != nil {
+				return
+			}
+			conflict = strings.Contains(result, "<<<<<<< ours") || strings.Contains(result, ">>>>>>> theirs")
+			if conflict {
+				if err = survey.AskOne(&survey.Confirm{Message: "There are still conflicts, continue to resolve them?"},
+					&ok); err != nil {
+					return
+				}
+				if !ok {
+					err = fmt.Errorf("the conflicts of the Jenkinsfile of pipeline %s/%s are not resolved", ns, name)
+					return
+				}
+			}
+		}
+	}
+	cmd.Print(unifiedDiff("Jenkinsfile", theirs, result))
+	return
+}
+
+func editText(message, text string) (result string, err error) {
+	prompt := &survey.Editor{
+		Message:       message,
+		FileName:      "*.groovy",
+		Default:       text,
+		HideDefault:   true,
+		AppendDefault: true,
+	}
+	err = survey.AskOne(prompt, &result, survey.WithStdio(os.Stdin, os.Stdout, os.Stderr))
 	return
 }
 
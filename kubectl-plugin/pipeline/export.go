@@ -0,0 +1,359 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/jenkinsfile"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	exportProjectFile     = "devopsproject.yaml"
+	exportCredentialsFile = "credentials.yaml"
+	exportPipelinesDir    = "pipelines"
+
+	credentialSecretTypePrefix = "credential.devops.kubesphere.io/"
+)
+
+// credentialReference represents a credential which is referenced by Pipelines
+type credentialReference struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type,omitempty"`
+	UsedBy []string `json:"usedBy"`
+}
+
+func newPipelineExportCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineExportOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export a DevOps project and its Pipelines as YAML files",
+		Long: `Export a DevOps project and its Pipelines as YAML files
+The Jenkinsfiles will be written as separate .groovy files. Only the references of credentials will be exported.`,
+		Example: "ks pip export my-project -d ./out",
+		Args:    cobra.ExactArgs(1),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.dir, "dir", "d", ".",
+		"The directory to write the files")
+	return
+}
+
+type pipelineExportOption struct {
+	dir string
+
+	// inner fields
+	client dynamic.Interface
+}
+
+func (o *pipelineExportOption) preRunE(cmd *cobra.Command, _ []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	return
+}
+
+func (o *pipelineExportOption) runE(cmd *cobra.Command, args []string) (err error) {
+	ctx := context.TODO()
+	var project *unstructured.Unstructured
+	if project, err = option.GetDevOpsProject(ctx, o.client, args[0]); err != nil {
+		return
+	}
+	ns := project.GetName()
+
+	if err = os.MkdirAll(path.Join(o.dir, exportPipelinesDir), 0750); err != nil {
+		return
+	}
+	if err = writeYAMLFile(path.Join(o.dir, exportProjectFile), cleanObject(project)); err != nil {
+		return
+	}
+
+	var pipelineList *unstructured.UnstructuredList
+	if pipelineList, err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).List(ctx, metav1.ListOptions{}); err != nil {
+		err = fmt.Errorf("failed to get Pipeline list in '%s', error: %v", ns, err)
+		return
+	}
+
+	credentials := map[string]*credentialReference{}
+	for i := range pipelineList.Items {
+		pip := cleanObject(&pipelineList.Items[i])
+		pip.SetNamespace("")
+
+		ids, parseErr := getPipelineCredentialIDs(pip)
+		if parseErr != nil {
+			cmd.PrintErrf("failed to parse the Jenkinsfile of Pipeline %s, its credentials are not exported, error: %v\n",
+				pip.GetName(), parseErr)
+		}
+		for _, id := range ids {
+			if _, ok := credentials[id]; !ok {
+				credentials[id] = &credentialReference{Name: id}
+			}
+			credentials[id].UsedBy = append(credentials[id].UsedBy, pip.GetName())
+		}
+
+		if script, ok, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "jenkinsfile"); ok {
+			unstructured.RemoveNestedField(pip.Object, "spec", "pipeline", "jenkinsfile")
+			if err = ioutil.WriteFile(path.Join(o.dir, exportPipelinesDir, pip.GetName()+".groovy"), []byte(script), 0640); err != nil {
+				return
+			}
+		}
+		if err = writeYAMLFile(path.Join(o.dir, exportPipelinesDir, pip.GetName()+".yaml"), pip); err != nil {
+			return
+		}
+		cmd.Printf("Pipeline %s/%s exported\n", ns, pip.GetName())
+	}
+
+	var secretList *unstructured.UnstructuredList
+	if secretList, err = o.client.Resource(types.GetSecretSchema()).Namespace(ns).List(ctx, metav1.ListOptions{}); err != nil {
+		err = fmt.Errorf("failed to get credential list in '%s', error: %v", ns, err)
+		return
+	}
+	for _, secret := range secretList.Items {
+		secretType, _, _ := unstructured.NestedString(secret.Object, "type")
+		if reference, ok := credentials[secret.GetName()]; ok && strings.HasPrefix(secretType, credentialSecretTypePrefix) {
+			reference.Type = secretType
+		}
+	}
+
+	references := make([]*credentialReference, 0, len(credentials))
+	for _, reference := range credentials {
+		references = append(references, reference)
+	}
+	sort.Slice(references, func(i, j int) bool {
+		return references[i].Name < references[j].Name
+	})
+	err = writeYAMLFile(path.Join(o.dir, exportCredentialsFile), references)
+	return
+}
+
+func newPipelineImportCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineImportOption{
+		PipelineCreateOption: option.PipelineCreateOption{
+			Client: client,
+		},
+	}
+	cmd = &cobra.Command{
+		Use:     "import",
+		Short:   "Import a DevOps project and its Pipelines from the exported YAML files",
+		Example: "ks pip import -d ./out --workspace ws --project my-project",
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.dir, "dir", "d", ".",
+		"The directory which contains the exported files")
+	flags.StringVarP(&opt.Workspace, "workspace", "", "",
+		"The workspace name of KubeSphere cluster, take the one of exported DevOps project if it's empty")
+	flags.StringVarP(&opt.Project, "project", "", "",
+		"The DevOps project name of KubeSphere cluster, take the one of exported DevOps project if it's empty")
+	flags.BoolVarP(&opt.SkipCheck, "skip-check", "", false, "Skip the workspace check")
+	return
+}
+
+type pipelineImportOption struct {
+	dir string
+	option.PipelineCreateOption
+}
+
+func (o *pipelineImportOption) preRunE(cmd *cobra.Command, _ []string) (err error) {
+	if o.Client == nil {
+		o.Client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	project := &unstructured.Unstructured{}
+	if err = readYAMLFile(path.Join(o.dir, exportProjectFile), project); err != nil {
+		return
+	}
+	if o.Project == "" {
+		o.Project = project.GetGenerateName()
+	}
+	if o.Workspace == "" {
+		o.Workspace = project.GetLabels()["kubesphere.io/workspace"]
+	}
+
+	if o.Project == "" {
+		err = fmt.Errorf("please provide the name of DevOps project")
+	} else if o.Workspace == "" && !o.SkipCheck {
+		err = fmt.Errorf("please provide the name of workspace")
+	}
+	return
+}
+
+func (o *pipelineImportOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	ctx := context.TODO()
+	var wsID string
+	if !o.SkipCheck {
+		var ws *unstructured.Unstructured
+		if ws, err = o.CheckWorkspace(); err != nil {
+			return
+		}
+		wsID = string(ws.GetUID())
+	}
+
+	var project *unstructured.Unstructured
+	if project, err = o.CheckDevOpsProject(wsID); err != nil {
+		err = fmt.Errorf("cannot find devopsProject %s, error %v", o.Project, err)
+		return
+	}
+	ns := project.GetName()
+
+	var files []string
+	if files, err = listFiles(path.Join(o.dir, exportPipelinesDir), ".yaml"); err != nil {
+		return
+	}
+	for _, file := range files {
+		pip := &unstructured.Unstructured{}
+		if err = readYAMLFile(file, pip); err != nil {
+			return
+		}
+		pip.SetNamespace(ns)
+
+		groovyFile := strings.TrimSuffix(file, ".yaml") + ".groovy"
+		if script, readErr := ioutil.ReadFile(groovyFile); readErr == nil {
+			if err = unstructured.SetNestedField(pip.Object, string(script), "spec", "pipeline", "jenkinsfile"); err != nil {
+				return
+			}
+		}
+
+		if _, err = o.Client.Resource(types.GetPipelineSchema()).Namespace(ns).Create(ctx, pip, metav1.CreateOptions{}); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				err = fmt.Errorf("failed to create Pipeline %s/%s, error: %v", ns, pip.GetName(), err)
+				return
+			}
+			cmd.Printf("Pipeline %s/%s already exists, skipped\n", ns, pip.GetName())
+		} else {
+			cmd.Printf("Pipeline %s/%s imported\n", ns, pip.GetName())
+		}
+	}
+
+	var references []credentialReference
+	if err = readYAMLFile(path.Join(o.dir, exportCredentialsFile), &references); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	for _, reference := range references {
+		if _, getErr := o.Client.Resource(types.GetSecretSchema()).Namespace(ns).Get(ctx, reference.Name,
+			metav1.GetOptions{}); getErr != nil {
+			cmd.PrintErrf("credential '%s' is not found in '%s', it's used by Pipelines: %v\n", reference.Name, ns, reference.UsedBy)
+		}
+	}
+	return
+}
+
+// getPipelineCredentialIDs returns the IDs of credentials which are used by a Pipeline
+func getPipelineCredentialIDs(pip *unstructured.Unstructured) (ids []string, err error) {
+	found := map[string]bool{}
+	collectCredentialIDs(pip.Object["spec"], found)
+
+	if script, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "jenkinsfile"); script != "" {
+		var scriptIDs []string
+		if scriptIDs, err = jenkinsfile.ParseCredentialIDs(script); err != nil {
+			return
+		}
+		for _, id := range scriptIDs {
+			found[id] = true
+		}
+	}
+
+	ids = make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return
+}
+
+// collectCredentialIDs finds all the credential_id fields, such as the ones of SCM sources
+func collectCredentialIDs(obj interface{}, found map[string]bool) {
+	switch val := obj.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if id, ok := item.(string); ok && key == "credential_id" && id != "" {
+				found[id] = true
+			} else {
+				collectCredentialIDs(item, found)
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			collectCredentialIDs(item, found)
+		}
+	}
+}
+
+// cleanObject returns a copy of the object without the cluster-specific metadata and status
+func cleanObject(obj *unstructured.Unstructured) (result *unstructured.Unstructured) {
+	result = obj.DeepCopy()
+	if result.GetGenerateName() != "" {
+		// the name is generated by the cluster
+		result.SetName("")
+	}
+	result.SetUID("")
+	result.SetResourceVersion("")
+	result.SetGeneration(0)
+	result.SetCreationTimestamp(metav1.Time{})
+	result.SetManagedFields(nil)
+	result.SetOwnerReferences(nil)
+	// the finalizers are added by the controller of the target cluster
+	result.SetFinalizers(nil)
+
+	annotations := result.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	result.SetAnnotations(annotations)
+	unstructured.RemoveNestedField(result.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(result.Object, "status")
+	return
+}
+
+func writeYAMLFile(file string, obj interface{}) (err error) {
+	var data []byte
+	if data, err = yaml.Marshal(obj); err == nil {
+		err = ioutil.WriteFile(file, data, 0640)
+	}
+	return
+}
+
+func readYAMLFile(file string, obj interface{}) (err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(file); err == nil {
+		if err = yaml.Unmarshal(data, obj); err != nil {
+			err = fmt.Errorf("failed to parse file %s, error: %v", file, err)
+		}
+	}
+	return
+}
+
+// listFiles returns the files which have the given extension in a directory
+func listFiles(dir, ext string) (files []string, err error) {
+	var entries []os.FileInfo
+	if entries, err = ioutil.ReadDir(dir); err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ext) {
+			files = append(files, path.Join(dir, entry.Name()))
+		}
+	}
+	return
+}
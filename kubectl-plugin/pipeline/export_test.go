@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newFakeDevOpsProject(name, generateName, ws string) *unstructured.Unstructured {
+	project := &unstructured.Unstructured{}
+	project.SetAPIVersion("devops.kubesphere.io/v1alpha3")
+	project.SetKind("DevOpsProject")
+	project.SetName(name)
+	project.SetGenerateName(generateName)
+	project.SetUID("uid")
+	project.SetResourceVersion("1")
+	project.SetLabels(map[string]string{"kubesphere.io/workspace": ws})
+	return project
+}
+
+func newFakePipeline(ns, name, jenkinsfile string) *unstructured.Unstructured {
+	pip := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"type": option.NoScmPipelineType,
+			"pipeline": map[string]interface{}{
+				"name":        name,
+				"jenkinsfile": jenkinsfile,
+			},
+		},
+		"status": map[string]interface{}{},
+	}}
+	pip.SetAPIVersion("devops.kubesphere.io/v1alpha3")
+	pip.SetKind("Pipeline")
+	pip.SetNamespace(ns)
+	pip.SetName(name)
+	pip.SetUID("uid")
+	pip.SetResourceVersion("1")
+	return pip
+}
+
+func TestExportAndImport(t *testing.T) {
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"type": "credential.devops.kubesphere.io/basic-auth",
+	}}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace("proj-abc")
+	secret.SetName("git")
+
+	source := newFakePipeline("proj-abc", "pip", "pipeline { stages { stage('a') { steps { git(credentialsId: 'git') } } } }")
+	source.SetFinalizers([]string{"pipeline.finalizers.kubesphere.io"})
+	source.SetAnnotations(map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"})
+	exportOpt := &pipelineExportOption{
+		dir: t.TempDir(),
+		client: newFakeDynamicClient(newFakeDevOpsProject("proj-abc", "proj", "ws"), secret, source,
+			newFakePipeline("proj-abc", "broken", "pipeline { stages { stage('a') { steps { echo 'a } } } }")),
+	}
+	cmd := &cobra.Command{}
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	assert.Nil(t, exportOpt.runE(cmd, []string{"proj"}))
+	// the Pipeline is exported even if its Jenkinsfile cannot be parsed
+	assert.Contains(t, buf.String(), "failed to parse the Jenkinsfile of Pipeline broken")
+	assert.Contains(t, buf.String(), "Pipeline proj-abc/broken exported")
+
+	data, err := ioutil.ReadFile(path.Join(exportOpt.dir, exportPipelinesDir, "pip.yaml"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "uid")
+	assert.NotContains(t, string(data), "resourceVersion")
+	assert.NotContains(t, string(data), "jenkinsfile")
+	assert.NotContains(t, string(data), "status")
+	assert.NotContains(t, string(data), "finalizers")
+	assert.NotContains(t, string(data), "last-applied-configuration")
+	data, err = ioutil.ReadFile(path.Join(exportOpt.dir, exportCredentialsFile))
+	assert.Nil(t, err)
+	assert.Equal(t, `- name: git
+  type: credential.devops.kubesphere.io/basic-auth
+  usedBy:
+  - pip
+`, string(data))
+
+	client := newFakeDynamicClient(newFakeDevOpsProject("another-xyz", "another", "ws"))
+	importOpt := &pipelineImportOption{dir: exportOpt.dir}
+	importOpt.Client = client
+	importOpt.Project = "another"
+	importOpt.SkipCheck = true
+	assert.Nil(t, importOpt.preRunE(cmd, nil))
+	assert.Equal(t, "ws", importOpt.Workspace)
+
+	buf.Reset()
+	assert.Nil(t, importOpt.runE(cmd, nil))
+	assert.Contains(t, buf.String(), "Pipeline another-xyz/pip imported")
+	assert.Contains(t, buf.String(), "credential 'git' is not found in 'another-xyz'")
+
+	pip, err := client.Resource(types.GetPipelineSchema()).Namespace("another-xyz").Get(context.TODO(), "pip", metav1.GetOptions{})
+	assert.Nil(t, err)
+	script, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "jenkinsfile")
+	assert.Contains(t, script, "credentialsId: 'git'")
+
+	// import it again
+	buf.Reset()
+	assert.Nil(t, importOpt.runE(cmd, nil))
+	assert.Contains(t, buf.String(), "Pipeline another-xyz/pip already exists, skipped")
+}
@@ -6,20 +6,20 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/go-openapi/strfmt"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
-	devopsclient "github.com/kubesphere/ks-devops-client-go/client"
-	"github.com/kubesphere/ks-devops-client-go/client/dev_ops_pipeline"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/flowcontrol"
 )
 
 func newGCCmd(client dynamic.Interface) (cmd *cobra.Command) {
@@ -30,9 +30,12 @@ func newGCCmd(client dynamic.Interface) (cmd *cobra.Command) {
 		},
 	}
 	cmd = &cobra.Command{
-		Use:     "gc",
-		Short:   "Garbage collector for PipelineRuns",
-		Long:    "Clean all those old PipelineRuns by age or count",
+		Use:   "gc",
+		Short: "Garbage collector for PipelineRuns",
+		Long:  "Clean all those old PipelineRuns by age or count",
+		Example: `ks pip gc --namespaces devops-ns --dry-run
+ks pip gc --clean-pipelinerun --abort-pipelinerun --report json
+ks pip gc --archive-to s3://bucket/pipelineruns --archive-s3-endpoint http://minio:9000`,
 		PreRunE: opt.preRunE,
 		RunE:    opt.runE,
 	}
@@ -52,11 +55,43 @@ func newGCCmd(client dynamic.Interface) (cmd *cobra.Command) {
 		"Whether abort pipelineruns that does not finished")
 	flags.DurationVarP(&opt.ageToAbort, "age-to-abort", "", 7*24*time.Hour,
 		"If a pipelinerun has been created than this age and has not finished yet, it will be aborted")
-	flags.StringVarP(&opt.devopsAPIHost, "devops-api-host", "", "devops-apiserver.kubesphere-devops-system.svc:9090",
-		"The devops apiserver address")
-	flags.StringArrayVarP(&opt.devopsAPISchemes, "devops-api-schemes", "", []string{"http"},
-		"The schemes to connect to devops apiserver")
+	flags.BoolVarP(&opt.dryRun, "dry-run", "", false,
+		"Only report the PipelineRuns which would be deleted or aborted, the report is a table by default")
+	flags.StringVarP(&opt.report, "report", "", "",
+		"Output a report of the PipelineRuns which are deleted, aborted or kept, supported formats: json, yaml, table")
+	flags.BoolVarP(&opt.daemon, "daemon", "", false,
+		"Run the garbage collector continuously, it's supposed to be used inside the cluster")
+	flags.DurationVarP(&opt.interval, "interval", "", time.Hour,
+		"The interval between two passes of the garbage collector in the daemon mode")
+	flags.StringVarP(&opt.metricsAddress, "metrics-address", "", ":8080",
+		"The address to serve the Prometheus metrics and health endpoints in the daemon mode")
+	flags.BoolVarP(&opt.leaderElect, "leader-elect", "", true,
+		"Enable the leader election in the daemon mode, make sure there is only one active garbage collector")
+	flags.StringVarP(&opt.leaseNamespace, "lease-namespace", "", getEnvOrDefault("POD_NAMESPACE", gcDefaultNamespace),
+		"The namespace of the Lease which is used for the leader election")
+	flags.IntVarP(&opt.concurrency, "concurrency", "", 4,
+		"The number of namespaces which are processed at the same time")
+	flags.DurationVarP(&opt.namespaceTimeout, "namespace-timeout", "", 10*time.Minute,
+		"The timeout of processing a namespace, zero means no timeout")
+	flags.Float32VarP(&opt.qps, "qps", "", 20,
+		"The maximum QPS to the Kubernetes API, zero means no limit")
+	flags.IntVarP(&opt.burst, "burst", "", 30,
+		"The maximum burst of the requests to the Kubernetes API")
+	flags.Float32VarP(&opt.devopsAPIQPS, "devops-api-qps", "", 10,
+		"The maximum QPS to the devops apiserver, zero means no limit")
+	flags.IntVarP(&opt.devopsAPIBurst, "devops-api-burst", "", 20,
+		"The maximum burst of the requests to the devops apiserver")
+	flags.StringVarP(&opt.archiveTo, "archive-to", "", "",
+		"Archive the YAML, log, stage nodes and artifacts, such as the test reports, of the PipelineRuns as tar.gz "+
+			"bundles before deleting them. It could be a local directory or s3://bucket/prefix")
+	flags.StringVarP(&opt.archiveS3Endpoint, "archive-s3-endpoint", "", "",
+		"The endpoint of the S3 compatible storage, such as MinIO. The credentials are read from the AWS environment variables")
+	flags.StringVarP(&opt.archiveS3Region, "archive-s3-region", "", "us-east-1",
+		"The region of the S3 bucket")
+	opt.addDevOpsAPIFlags(flags)
+	cmd.AddCommand(newGCInstallCmd())
 	_ = cmd.RegisterFlagCompletionFunc("condition", common.ArrayCompletion(conditionAnd, conditionIgnore))
+	_ = cmd.RegisterFlagCompletionFunc("report", common.ArrayCompletion(outputFormatJSON, outputFormatYAML, outputFormatTable))
 	return
 }
 
@@ -65,25 +100,74 @@ const (
 	conditionIgnore = "ignoreTime"
 )
 
+// the annotations to override the policy of the garbage collector,
+// gcMaxCountAnnotationKey and gcMaxAgeAnnotationKey work on a DevOpsProject or a Pipeline,
+// and gcKeepAnnotationKey pins a PipelineRun
+const (
+	gcMaxCountAnnotationKey = "devops.kubesphere.io/gc-max-count"
+	gcMaxAgeAnnotationKey   = "devops.kubesphere.io/gc-max-age"
+	gcKeepAnnotationKey     = "devops.kubesphere.io/gc-keep"
+)
+
 type gcOption struct {
-	cleanPipelinerun bool
-	maxCount         uint
-	maxAge           time.Duration
-	condition        string
-	namespaces       []string
-	abortPipelinerun bool
-	ageToAbort       time.Duration
-	devopsAPIHost    string
-	devopsAPISchemes []string
+	cleanPipelinerun  bool
+	maxCount          uint
+	maxAge            time.Duration
+	condition         string
+	namespaces        []string
+	abortPipelinerun  bool
+	ageToAbort        time.Duration
+	dryRun            bool
+	report            string
+	daemon            bool
+	interval          time.Duration
+	metricsAddress    string
+	leaderElect       bool
+	leaseNamespace    string
+	concurrency       int
+	namespaceTimeout  time.Duration
+	qps               float32
+	burst             int
+	archiveTo         string
+	archiveS3Endpoint string
+	archiveS3Region   string
+	devopsAPIOption
 
 	// inner fields
 	client dynamic.Interface
 	option.PipelineCreateOption
-	devopsClient *devopsclient.KubeSphereDevOps
+	reportItems   []gcReportItem
+	reportLock    sync.Mutex
+	allNamespaces bool
+	k8sLimiter    flowcontrol.RateLimiter
+	archiver      gcArchiver
 }
 
 func (o *gcOption) preRunE(cmd *cobra.Command, args []string) (err error) {
-	if len(o.namespaces) == 0 {
+	if o.dryRun && o.report == "" {
+		o.report = outputFormatTable
+	}
+	switch o.report {
+	case "", outputFormatJSON, outputFormatYAML, outputFormatTable:
+	default:
+		err = fmt.Errorf("not supported report format: %s", o.report)
+		return
+	}
+	if o.concurrency < 1 {
+		err = fmt.Errorf("the concurrency must be positive, got: %d", o.concurrency)
+		return
+	}
+	if o.qps > 0 {
+		o.k8sLimiter = flowcontrol.NewTokenBucketRateLimiter(o.qps, o.burst)
+	}
+	if o.archiveTo != "" {
+		if o.archiver, err = newGCArchiver(o.archiveTo, o.archiveS3Endpoint, o.archiveS3Region); err != nil {
+			err = fmt.Errorf("failed to init the archiver, error: %v", err)
+			return
+		}
+	}
+
+	if o.allNamespaces = len(o.namespaces) == 0; o.allNamespaces {
 		if err = o.getAllDevOpsNamespace(); err != nil {
 			log.Errorf("failed to get all DevOps project namespace, error: %+v", err)
 			return
@@ -93,13 +177,12 @@ func (o *gcOption) preRunE(cmd *cobra.Command, args []string) (err error) {
 	return
 }
 
-func (o *gcOption) initDevopsClient() error {
-	cfg := &devopsclient.TransportConfig{
-		Host:    o.devopsAPIHost,
-		Schemes: o.devopsAPISchemes,
+// waitK8sLimiter blocks until the next request to the Kubernetes API is allowed
+func (o *gcOption) waitK8sLimiter(ctx context.Context) error {
+	if o.k8sLimiter == nil {
+		return nil
 	}
-	o.devopsClient = devopsclient.NewHTTPClientWithConfig(strfmt.Default, cfg)
-	return nil
+	return o.k8sLimiter.Wait(ctx)
 }
 
 func (o *gcOption) getAllDevOpsNamespace() (err error) {
@@ -115,20 +198,62 @@ func (o *gcOption) getAllDevOpsNamespace() (err error) {
 	return
 }
 
-func (o *gcOption) cleanPipelineRunInNamespace(namespace string) (err error) {
+// cleanPipelineRunInNamespace deletes the PipelineRuns of a namespace by max-count and max-age. The PipelineRuns of
+// the Pipelines which override the policy by annotations are counted per Pipeline, the others are counted together.
+func (o *gcOption) cleanPipelineRunInNamespace(ctx context.Context, namespace string, policy *gcPolicy) (err error) {
 	var pipelinerunList *unstructured.UnstructuredList
-	if pipelinerunList, err = o.GetUnstructuredListInNamespace(namespace, types.GetPipelineRunSchema()); err != nil {
+	if err = o.waitK8sLimiter(ctx); err == nil {
+		pipelinerunList, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	}
+	if err != nil {
 		err = fmt.Errorf("failed to get PipelineRun list, error: %v", err)
 		return
 	}
 
-	items := pipelinerunList.Items
-	toDelete := len(items) - int(o.maxCount)
+	var pipelinePolicies map[string]*gcPolicy
+	if pipelinePolicies, err = o.getPipelinePolicies(ctx, namespace, policy); err != nil {
+		return
+	}
+
+	var items []unstructured.Unstructured
+	pipelineItems := map[string][]unstructured.Unstructured{}
+	for _, item := range pipelinerunList.Items {
+		if pipeline := getPipelineRunPipeline(&item); pipelinePolicies[pipeline] != nil {
+			pipelineItems[pipeline] = append(pipelineItems[pipeline], item)
+		} else {
+			items = append(items, item)
+		}
+	}
+	if err = o.cleanPipelineRuns(ctx, namespace, items, policy); err != nil {
+		return
+	}
+
+	pipelines := make([]string, 0, len(pipelineItems))
+	for pipeline := range pipelineItems {
+		pipelines = append(pipelines, pipeline)
+	}
+	sort.Strings(pipelines)
+	for _, pipeline := range pipelines {
+		if err = o.cleanPipelineRuns(ctx, namespace, pipelineItems[pipeline], pipelinePolicies[pipeline]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// cleanPipelineRuns deletes the oldest PipelineRuns which exceed the max-count, the pinned ones are not counted
+func (o *gcOption) cleanPipelineRuns(ctx context.Context, namespace string, items []unstructured.Unstructured, policy *gcPolicy) (err error) {
+	toDelete := len(items) - int(policy.maxCount)
+	for i := range items {
+		if isPinned(&items[i]) {
+			toDelete--
+		}
+	}
 	if toDelete < 1 {
 		return
 	}
 
-	ascOrderWithCompletionTime(pipelinerunList.Items)
+	ascOrderWithCompletionTime(items)
 
 	for i := range items {
 		item := items[i]
@@ -137,14 +262,41 @@ func (o *gcOption) cleanPipelineRunInNamespace(namespace string) (err error) {
 		if toDelete <= 0 {
 			break
 		}
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		if isPinned(&item) {
+			o.addReportItem(namespace, getPipelineRunPipeline(&item), item.GetName(), gcActionKeep, gcReasonPinned)
+			continue
+		}
 
-		if (o.condition == conditionAnd && okToDelete(item.Object, o.maxAge)) || o.condition == conditionIgnore {
-			delErr := o.client.Resource(types.GetPipelineRunSchema()).Namespace(namespace).Delete(
-				context.TODO(), item.GetName(), metav1.DeleteOptions{})
+		if (o.condition == conditionAnd && okToDelete(item.Object, policy.maxAge)) || o.condition == conditionIgnore {
+			reason := gcReasonMaxCount
+			if o.condition == conditionAnd {
+				reason = fmt.Sprintf("%s, %s", gcReasonMaxCount, gcReasonMaxAge)
+			}
+			o.addReportItem(namespace, getPipelineRunPipeline(&item), item.GetName(), gcActionDelete, reason)
+			if o.dryRun {
+				toDelete--
+				continue
+			}
+
+			if archiveErr := o.archivePipelineRun(ctx, &item); archiveErr != nil {
+				log.Errorf("failed to archive PipelineRun %s/%s, skip deleting it. error: %v", namespace, item.GetName(), archiveErr)
+				gcMetrics.errors.WithLabelValues(namespace).Inc()
+				continue
+			}
+			delErr := o.waitK8sLimiter(ctx)
+			if delErr == nil {
+				delErr = o.client.Resource(types.GetPipelineRunSchema()).Namespace(namespace).Delete(
+					ctx, item.GetName(), metav1.DeleteOptions{})
+			}
 			if delErr != nil {
 				log.Errorf("failed to delete PipelineRun %s/%s, error: %v", item.GetName(), namespace, delErr)
 			} else {
 				toDelete--
+				gcMetrics.deleted.WithLabelValues(namespace).Inc()
 				log.Errorf("ok to delete PipelineRun %s/%s", item.GetName(), namespace)
 			}
 		}
@@ -152,59 +304,190 @@ func (o *gcOption) cleanPipelineRunInNamespace(namespace string) (err error) {
 	return
 }
 
-func (o *gcOption) runE(cmd *cobra.Command, args []string) error {
-	// clean pipelinerun of pipeline with days_to_keep and num_to_keep
-	for _, ns := range o.namespaces {
-		unList, err := o.GetUnstructuredListInNamespace(ns, types.GetPipelineSchema())
-		if err != nil {
-			cmd.PrintErrf("failed to get Pipeline in '%s', error: %+v\n", ns, err)
-			return err
+// gcPolicy is the max-count and max-age of the PipelineRuns
+type gcPolicy struct {
+	maxCount uint
+	maxAge   time.Duration
+	// overridden indicates the policy is set by the annotations of the DevOpsProject
+	overridden bool
+}
+
+// getNamespacePolicy returns the policy of a namespace, it could be overridden by the annotations of the DevOpsProject
+func (o *gcOption) getNamespacePolicy(ctx context.Context, namespace string) (policy *gcPolicy) {
+	policy = &gcPolicy{maxCount: o.maxCount, maxAge: o.maxAge}
+	if err := o.waitK8sLimiter(ctx); err != nil {
+		return
+	}
+	project, err := o.client.Resource(types.GetDevOpsProjectSchema()).Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+
+	maxCount, maxAge := getPolicyAnnotations(project.GetAnnotations(), "DevOpsProject", namespace)
+	if maxCount != nil {
+		policy.maxCount = *maxCount
+		policy.overridden = true
+	}
+	if maxAge != nil {
+		policy.maxAge = *maxAge
+		policy.overridden = true
+	}
+	return
+}
+
+// getPipelinePolicies returns the policies of the Pipelines which override the namespace policy by annotations
+func (o *gcOption) getPipelinePolicies(ctx context.Context, namespace string, namespacePolicy *gcPolicy) (
+	policies map[string]*gcPolicy, err error) {
+	var pipelineList *unstructured.UnstructuredList
+	if err = o.waitK8sLimiter(ctx); err == nil {
+		pipelineList, err = o.client.Resource(types.GetPipelineSchema()).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to get Pipeline list, error: %v", err)
+		return
+	}
+
+	policies = map[string]*gcPolicy{}
+	for _, item := range pipelineList.Items {
+		maxCount, maxAge := getPolicyAnnotations(item.GetAnnotations(), "Pipeline", item.GetNamespace()+"/"+item.GetName())
+		if maxCount == nil && maxAge == nil {
+			continue
 		}
 
-		for _, un := range unList.Items {
-			log.Infof("### found pipeline: %s in namespace: %s", un.GetName(), ns)
-			pipeline, err := toPipeline(o, un)
-			if err != nil {
-				cmd.PrintErrf("parse unstructured pipeline to gcPipeline(%s) failed, err: %+v\n", un.GetName(), err)
-				continue
-			}
-			if err := pipeline.getPipelinerun(); err != nil {
-				cmd.PrintErrf("failed to get pipelinerun, error: %+v\n", err)
-				continue
-			}
-			if err := pipeline.clean(); err != nil {
-				cmd.PrintErrf("clean pipelinerun error: %+v\n", err)
-				continue
-			}
-			if err := pipeline.abort(); err != nil {
-				cmd.PrintErrf("abort pipelinerun error: %+v\n", err)
-				continue
-			}
+		policy := *namespacePolicy
+		if maxCount != nil {
+			policy.maxCount = *maxCount
 		}
+		if maxAge != nil {
+			policy.maxAge = *maxAge
+		}
+		policies[item.GetName()] = &policy
 	}
+	return
+}
 
-	if !o.cleanPipelinerun {
-		return nil
+// getPolicyAnnotations returns the max-count and max-age in the annotations of a DevOpsProject or Pipeline,
+// they are nil if not found. The invalid ones are ignored with a warning.
+func getPolicyAnnotations(annotations map[string]string, kind, name string) (maxCount *uint, maxAge *time.Duration) {
+	if val, ok := annotations[gcMaxCountAnnotationKey]; ok {
+		if count, err := strconv.ParseUint(val, 10, 32); err == nil {
+			result := uint(count)
+			maxCount = &result
+		} else {
+			log.Warnf("invalid annotation %s of %s %s, ignored. error: %v", gcMaxCountAnnotationKey, kind, name, err)
+		}
+	}
+	if val, ok := annotations[gcMaxAgeAnnotationKey]; ok {
+		if age, err := time.ParseDuration(val); err == nil {
+			maxAge = &age
+		} else {
+			log.Warnf("invalid annotation %s of %s %s, ignored. error: %v", gcMaxAgeAnnotationKey, kind, name, err)
+		}
 	}
-	log.Info("clean pipelinerun of dev-project by max-count and max-age ..")
+	return
+}
 
-	// keep below log output until replace it with a logger
-	//cmd.Printf("starting to gc PipelineRuns in %d namespaces\n", len(o.namespaces))
-	errorsNs := []string{}
+func isPinned(pipelineRun *unstructured.Unstructured) bool {
+	return pipelineRun.GetAnnotations()[gcKeepAnnotationKey] == "true"
+}
 
+func (o *gcOption) runE(cmd *cobra.Command, args []string) error {
+	if o.daemon {
+		return o.runDaemon(cmd)
+	}
+	return o.gcOnce(context.TODO(), cmd)
+}
+
+// gcOnce cleans and aborts the PipelineRuns of all the namespaces for one time.
+// The namespaces are processed concurrently, the errors of them are aggregated.
+// It stops deleting and aborting PipelineRuns once the context is done.
+func (o *gcOption) gcOnce(ctx context.Context, cmd *cobra.Command) error {
+	var (
+		errs     []error
+		errsLock sync.Mutex
+	)
+	group := &errgroup.Group{}
+	if o.concurrency > 0 {
+		group.SetLimit(o.concurrency)
+	}
 	for i := range o.namespaces {
 		ns := o.namespaces[i]
-		if err := o.cleanPipelineRunInNamespace(ns); err != nil {
-			cmd.PrintErrf("failed to clean PipelineRuns in '%s'\n", ns)
-			errorsNs = append(errorsNs, ns)
+		group.Go(func() error {
+			if err := o.gcNamespace(ctx, cmd, ns); err != nil {
+				cmd.PrintErrf("failed to gc PipelineRuns in '%s', error: %v\n", ns, err)
+				gcMetrics.errors.WithLabelValues(ns).Inc()
+
+				errsLock.Lock()
+				errs = append(errs, fmt.Errorf("namespace %s: %v", ns, err))
+				errsLock.Unlock()
+			}
+			// don't stop other namespaces
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	if err := o.printReport(cmd.OutOrStdout()); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		log.Errorf("gc failed in %d namespaces", len(errs))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// gcNamespace cleans and aborts the PipelineRuns of a namespace within the namespace timeout
+func (o *gcOption) gcNamespace(ctx context.Context, cmd *cobra.Command, ns string) (err error) {
+	if o.namespaceTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.namespaceTimeout)
+		defer cancel()
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	policy := o.getNamespacePolicy(ctx, ns)
+
+	// clean pipelinerun of pipeline with days_to_keep and num_to_keep
+	var unList *unstructured.UnstructuredList
+	if err = o.waitK8sLimiter(ctx); err == nil {
+		unList, err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).List(ctx, metav1.ListOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Pipeline, error: %v", err)
+	}
+
+	for _, un := range unList.Items {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		log.Infof("### found pipeline: %s in namespace: %s", un.GetName(), ns)
+		pipeline, err := toPipeline(o, un, policy)
+		if err != nil {
+			cmd.PrintErrf("parse unstructured pipeline to gcPipeline(%s) failed, err: %+v\n", un.GetName(), err)
+			continue
+		}
+		if err := pipeline.getPipelinerun(ctx); err != nil {
+			cmd.PrintErrf("failed to get pipelinerun, error: %+v\n", err)
+			continue
+		}
+		if err := pipeline.clean(ctx); err != nil {
+			cmd.PrintErrf("clean pipelinerun error: %+v\n", err)
+			continue
+		}
+		if err := pipeline.abort(ctx); err != nil {
+			cmd.PrintErrf("abort pipelinerun error: %+v\n", err)
+			continue
 		}
 	}
 
-	if len(errorsNs) > 0 {
-		log.Errorf("gc failed in %d namespaces: %v", len(errorsNs), errorsNs)
-		return fmt.Errorf("gc failed")
+	if !o.cleanPipelinerun {
+		return
 	}
-	return nil
+	log.Infof("clean pipelinerun of dev-project %s by max-count and max-age ..", ns)
+	return o.cleanPipelineRunInNamespace(ctx, ns, policy)
 }
 
 type gcPipeline struct {
@@ -217,15 +500,19 @@ type gcPipeline struct {
 	discard    bool
 	daysToKeep int
 	numToKeep  int
+	// maxAge overrides the daysToKeep if it's positive
+	maxAge time.Duration
 
 	pipelinerunList []*gcPipelinerun
 }
 
-func (p *gcPipeline) getPipelinerun() (err error) {
-	ctx := context.TODO()
+func (p *gcPipeline) getPipelinerun(ctx context.Context) (err error) {
 	opts := metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("%s=%s", option.PipelinerunOwnerLabelKey, p.name),
 	}
+	if err = p.option.waitK8sLimiter(ctx); err != nil {
+		return
+	}
 	var wsList *unstructured.UnstructuredList
 	if wsList, err = p.option.Client.Resource(types.GetPipelineRunSchema()).Namespace(p.namespace).List(ctx, opts); err != nil {
 		return err
@@ -260,13 +547,13 @@ func (p *gcPipeline) ascPipelinerun() {
 	}
 }
 
-func (p *gcPipeline) clean() (err error) {
+func (p *gcPipeline) clean(ctx context.Context) (err error) {
 	log.Infof("clean pipelinerun of pipeline: %s ..", p.name)
 	if !p.discard {
 		log.Warn("the discarder of pipeline not found, ignore.")
 		return
 	}
-	if p.pType != option.NoScmPipelineType {
+	if p.pType != option.NoScmPipelineType && p.pType != option.MultiBranchPipelineType {
 		log.Warnf("the type of pipeline is %s, ignore.", p.pType)
 		return
 	}
@@ -276,28 +563,65 @@ func (p *gcPipeline) clean() (err error) {
 		return nil
 	}
 
-	deletingPipelinerunList := p.needToDelete()
+	deletingPipelinerunList, keepingPipelinerunList := p.needToDelete()
+	if p.pType == option.MultiBranchPipelineType && p.option.devopsClient != nil {
+		// the runs of the removed branches are deleted even if they are the last successful ones
+		if branches, branchErr := p.option.getPipelineBranches(ctx, p.namespace, p.name); branchErr != nil {
+			log.Warnf("failed to get the branches of pipeline: %s, ignore the removed branches. error: %v", p.name, branchErr)
+		} else if len(branches) > 0 {
+			removed := p.needToDeleteByBranches(branches)
+			deletingPipelinerunList = append(excludeGCPipelineruns(deletingPipelinerunList, removed), removed...)
+			keepingPipelinerunList = excludeGCPipelineruns(keepingPipelinerunList, removed)
+		}
+	}
+	for _, run := range keepingPipelinerunList {
+		p.option.addReportItem(p.namespace, p.name, run.name, gcActionKeep, run.reason)
+	}
 	for _, run := range deletingPipelinerunList {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		p.option.addReportItem(p.namespace, p.name, run.name, gcActionDelete, run.reason)
+		if p.option.dryRun {
+			continue
+		}
+
+		if archiveErr := p.option.archivePipelineRun(ctx, run.object); archiveErr != nil {
+			log.Errorf("failed to archive PipelineRun: %s, skip deleting it. error: %v", run.name, archiveErr)
+			gcMetrics.errors.WithLabelValues(p.namespace).Inc()
+			continue
+		}
 		log.Infof("delete pipelinerun: %s/%s ...", run.id, run.name)
+		if err = p.option.waitK8sLimiter(ctx); err != nil {
+			return
+		}
 		if err = p.option.client.Resource(types.GetPipelineRunSchema()).Namespace(p.namespace).Delete(
-			context.TODO(), run.name, metav1.DeleteOptions{}); err != nil {
+			ctx, run.name, metav1.DeleteOptions{}); err != nil {
 			log.Errorf("failed to delete PipelineRun: %s, error: %+v", run.name, err)
 			return err
 		}
+		gcMetrics.deleted.WithLabelValues(p.namespace).Inc()
 		log.Infof("pipelinerun: %s deleted.", run.name)
 	}
 	return
 }
 
-func (p *gcPipeline) abort() (err error) {
-	ctx := context.Background()
+func (p *gcPipeline) abort(ctx context.Context) (err error) {
 	if !p.option.abortPipelinerun {
 		log.Infof("the abortPipelinerun flag is not enabled")
 		return nil
 	}
 	log.Infof("abort pipelinerun of pipeline: %s ..", p.name)
 	for _, run := range p.pipelinerunList {
+		if err = ctx.Err(); err != nil {
+			return
+		}
 		if !run.isCompletion() && run.creationTime.Add(p.option.ageToAbort).Before(time.Now()) {
+			p.option.addReportItem(p.namespace, p.name, run.name, gcActionAbort, gcReasonAgeToAbort)
+			if p.option.dryRun {
+				continue
+			}
+
 			log.Infof("abort pipelinerun: %s ..", run.name)
 			abortErr := p.abortPipelinerun(ctx, run)
 			if abortErr != nil {
@@ -305,55 +629,118 @@ func (p *gcPipeline) abort() (err error) {
 				// we want to try all pipelineruns, so continue here
 				continue
 			}
+			gcMetrics.aborted.WithLabelValues(p.namespace).Inc()
 		}
 	}
 	return nil
 }
 
 func (p *gcPipeline) abortPipelinerun(ctx context.Context, run *gcPipelinerun) error {
-	if run.pType == option.MultiBranchPipelineType {
-		return p.abortMultiBranchPipelinerun(ctx, run)
-	}
-	return p.abortNoScmPipelinerun(ctx, run)
+	return p.option.stopRun(ctx, p.namespace, p.name, run.branch, run.id)
 }
 
-func (p *gcPipeline) abortNoScmPipelinerun(ctx context.Context, run *gcPipelinerun) error {
-	stopPipelineParams := &dev_ops_pipeline.StopPipelineParams{
-		Blocking:      aws.String("true"),
-		Body:          []int64{},
-		Devops:        p.namespace,
-		Pipeline:      p.name,
-		Run:           run.id,
-		TimeOutInSecs: aws.String("10"),
-		Context:       ctx,
+// needToDelete returns the PipelineRuns which need to be deleted, and the last-successful and last-stable ones
+// which are kept on purpose. The reason is set for each of them.
+// The discarder works on each branch of a multi-branch Pipeline.
+func (p *gcPipeline) needToDelete() (deleting, keeping []*gcPipelinerun) {
+	p.ascPipelinerun()
+	durationToKeep := time.Duration(p.daysToKeep*24) * time.Hour
+	if p.maxAge > 0 {
+		durationToKeep = p.maxAge
+	}
+	if p.pType != option.MultiBranchPipelineType {
+		return needToDeleteByDiscarder(p.pipelinerunList, durationToKeep, p.numToKeep)
+	}
+
+	var branches []string
+	branchRuns := map[string][]*gcPipelinerun{}
+	for _, run := range p.pipelinerunList {
+		if _, ok := branchRuns[run.branch]; !ok {
+			branches = append(branches, run.branch)
+		}
+		branchRuns[run.branch] = append(branchRuns[run.branch], run)
+	}
+	for _, branch := range branches {
+		branchDeleting, branchKeeping := needToDeleteByBranchDiscarder(branchRuns[branch], durationToKeep, p.numToKeep)
+		deleting = append(deleting, branchDeleting...)
+		keeping = append(keeping, branchKeeping...)
 	}
-	_, err := p.option.devopsClient.DevOpsPipeline.StopPipeline(stopPipelineParams)
-	return err
+	return
 }
 
-func (p *gcPipeline) abortMultiBranchPipelinerun(ctx context.Context, run *gcPipelinerun) error {
-	stopPipelineParams := &dev_ops_pipeline.StopBranchPipelineParams{
-		Blocking:      aws.String("true"),
-		Body:          []int64{},
-		Branch:        run.branch,
-		Devops:        p.namespace,
-		Pipeline:      p.name,
-		Run:           run.id,
-		TimeOutInSecs: aws.String("10"),
-		Context:       ctx,
+// needToDeleteByDiscarder applies the discarder to the PipelineRuns which are sorted by the completion time.
+// The last-successful and last-stable ones are kept from num_to_keep only, the pinned ones are always kept.
+// A non-positive durationToKeep means no age limit.
+func needToDeleteByDiscarder(runs []*gcPipelinerun, durationToKeep time.Duration, numToKeep int) (deleting, keeping []*gcPipelinerun) {
+	lastSuccessfulIndex, lastStableIndex := getLastCompletedIndexes(runs)
+
+	// clean by num_to_keep and day_to_keep
+	numLimitIndex := len(runs) - numToKeep
+	for i, pipelinerun := range runs {
+		if !pipelinerun.isCompletion() {
+			continue
+		}
+
+		if i < numLimitIndex {
+			if pipelinerun.keep || i == lastSuccessfulIndex || i == lastStableIndex { // ignore to delete last-stable and last-successful pipelinerun
+				numLimitIndex = numLimitIndex + 1
+				pipelinerun.reason = getKeepingReason(pipelinerun, i == lastSuccessfulIndex)
+				keeping = append(keeping, pipelinerun)
+			} else {
+				pipelinerun.reason = gcReasonNumToKeep
+				deleting = append(deleting, pipelinerun)
+			}
+		} else if durationToKeep > 0 && pipelinerun.isOverdue(durationToKeep) {
+			if pipelinerun.keep {
+				pipelinerun.reason = gcReasonPinned
+				keeping = append(keeping, pipelinerun)
+			} else {
+				pipelinerun.reason = gcReasonDaysToKeep
+				deleting = append(deleting, pipelinerun)
+			}
+		}
 	}
-	_, err := p.option.devopsClient.DevOpsPipeline.StopBranchPipeline(stopPipelineParams)
-	return err
+	return
 }
 
-func (p *gcPipeline) needToDelete() (deleting []*gcPipelinerun) {
-	p.ascPipelinerun()
+// needToDeleteByBranchDiscarder applies the discarder to the PipelineRuns of a branch which are sorted by the completion time.
+// A non-positive durationToKeep or numToKeep means no limit, the pinned, last-successful and last-stable ones are always kept.
+func needToDeleteByBranchDiscarder(runs []*gcPipelinerun, durationToKeep time.Duration, numToKeep int) (deleting, keeping []*gcPipelinerun) {
+	lastSuccessfulIndex, lastStableIndex := getLastCompletedIndexes(runs)
+
+	numLimitIndex := len(runs) - numToKeep
+	if numToKeep <= 0 {
+		numLimitIndex = 0
+	}
+	for i, pipelinerun := range runs {
+		if !pipelinerun.isCompletion() {
+			continue
+		}
+
+		if pipelinerun.keep || i == lastSuccessfulIndex || i == lastStableIndex {
+			if i < numLimitIndex || (durationToKeep > 0 && pipelinerun.isOverdue(durationToKeep)) {
+				if i < numLimitIndex {
+					numLimitIndex = numLimitIndex + 1
+				}
+				pipelinerun.reason = getKeepingReason(pipelinerun, i == lastSuccessfulIndex)
+				keeping = append(keeping, pipelinerun)
+			}
+		} else if i < numLimitIndex {
+			pipelinerun.reason = gcReasonNumToKeep
+			deleting = append(deleting, pipelinerun)
+		} else if durationToKeep > 0 && pipelinerun.isOverdue(durationToKeep) {
+			pipelinerun.reason = gcReasonDaysToKeep
+			deleting = append(deleting, pipelinerun)
+		}
+	}
+	return
+}
 
-	// get index of last_successful and last_stable pipelinerun
-	var lastSuccessfulIndex, lastStableIndex int
+// getLastCompletedIndexes returns the index of last-successful and last-stable PipelineRun, it's -1 if not found
+func getLastCompletedIndexes(runs []*gcPipelinerun) (lastSuccessfulIndex, lastStableIndex int) {
 	lastSuccessfulIndex = -1
 	lastStableIndex = -1
-	for i, pipelinerun := range p.pipelinerunList {
+	for i, pipelinerun := range runs {
 		if pipelinerun.isCompletion() {
 			if pipelinerun.phase == option.PipelinerunPhaseSucceeded {
 				lastSuccessfulIndex = i
@@ -361,22 +748,43 @@ func (p *gcPipeline) needToDelete() (deleting []*gcPipelinerun) {
 			lastStableIndex = i
 		}
 	}
+	return
+}
 
-	// clean by num_to_keep and day_to_keep
-	durationToKeep := time.Duration(p.daysToKeep*24) * time.Hour
-	numLimitIndex := len(p.pipelinerunList) - p.numToKeep
-	for i, pipelinerun := range p.pipelinerunList {
-		if pipelinerun.isCompletion() {
-			if i < numLimitIndex {
-				if i == lastSuccessfulIndex || i == lastStableIndex { // ignore to delete last-stable and last-successful pipelinerun
-					numLimitIndex = numLimitIndex + 1
-				} else {
-					deleting = append(deleting, pipelinerun)
-				}
-			} else if pipelinerun.isOverdue(durationToKeep) {
-				deleting = append(deleting, pipelinerun)
+func getKeepingReason(pipelinerun *gcPipelinerun, lastSuccessful bool) string {
+	switch {
+	case pipelinerun.keep:
+		return gcReasonPinned
+	case lastSuccessful:
+		return gcReasonLastSuccessful
+	default:
+		return gcReasonLastStable
+	}
+}
+
+// needToDeleteByBranches returns the completed PipelineRuns whose branches do not exist in the SCM anymore
+func (p *gcPipeline) needToDeleteByBranches(branches map[string]bool) (deleting []*gcPipelinerun) {
+	for _, run := range p.pipelinerunList {
+		if run.isCompletion() && !run.keep && run.branch != "" && !branches[run.branch] {
+			run.reason = gcReasonBranchRemoved
+			deleting = append(deleting, run)
+		}
+	}
+	return
+}
+
+func excludeGCPipelineruns(runs, excluded []*gcPipelinerun) (result []*gcPipelinerun) {
+	for _, run := range runs {
+		found := false
+		for _, item := range excluded {
+			if run == item {
+				found = true
+				break
 			}
 		}
+		if !found {
+			result = append(result, run)
+		}
 	}
 	return
 }
@@ -389,6 +797,12 @@ type gcPipelinerun struct {
 	branch         string
 	completionTime time.Time
 	creationTime   time.Time
+	// keep indicates if the PipelineRun is pinned
+	keep bool
+	// reason is the reason why the PipelineRun is deleted or kept
+	reason string
+	// object is the original PipelineRun, it's archived before deleting
+	object *unstructured.Unstructured
 }
 
 func (r *gcPipelinerun) isOverdue(maxAge time.Duration) bool {
@@ -399,7 +813,9 @@ func (r *gcPipelinerun) isCompletion() bool {
 	return !r.completionTime.IsZero()
 }
 
-func toPipeline(gcOpt *gcOption, u unstructured.Unstructured) (*gcPipeline, error) {
+// toPipeline parses the discarder of a Pipeline, the namespace policy is taken if it's overridden by the
+// DevOpsProject and the Pipeline has no discarder
+func toPipeline(gcOpt *gcOption, u unstructured.Unstructured, namespacePolicy *gcPolicy) (*gcPipeline, error) {
 	pipeline := &gcPipeline{
 		option:    gcOpt,
 		name:      u.GetName(),
@@ -447,7 +863,25 @@ func toPipeline(gcOpt *gcOption, u unstructured.Unstructured) (*gcPipeline, erro
 			}
 		}
 	}
-	return pipeline, err
+	if err != nil {
+		return nil, err
+	}
+
+	// the annotations of the Pipeline override the discarder, the missing one is taken from the namespace policy
+	// if there is no discarder
+	maxCount, maxAge := getPolicyAnnotations(u.GetAnnotations(), "Pipeline", u.GetNamespace()+"/"+u.GetName())
+	if !pipeline.discard && (maxCount != nil || maxAge != nil || namespacePolicy.overridden) {
+		pipeline.discard = true
+		pipeline.numToKeep = int(namespacePolicy.maxCount)
+		pipeline.maxAge = namespacePolicy.maxAge
+	}
+	if maxCount != nil {
+		pipeline.numToKeep = int(*maxCount)
+	}
+	if maxAge != nil {
+		pipeline.maxAge = *maxAge
+	}
+	return pipeline, nil
 }
 
 func toPipelinerun(u unstructured.Unstructured) (*gcPipelinerun, error) {
@@ -488,8 +922,10 @@ func toPipelinerun(u unstructured.Unstructured) (*gcPipelinerun, error) {
 		pType:        pType,
 		branch:       branch,
 		creationTime: creationTime,
+		keep:         isPinned(&u),
+		object:       &u,
 	}
-	if phase == option.PipelinerunPhaseSucceeded || phase == option.PipelinerunPhaseFailed || phase == option.PipelinerunPhaseCancelled {
+	if isCompletedPhase(phase) {
 		pipelinerun.completionTime, err = getCompletionTimeFromObject(u.Object)
 	}
 	return pipelinerun, err
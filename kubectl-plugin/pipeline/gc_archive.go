@@ -0,0 +1,231 @@
+package pipeline
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere/ks-devops-client-go/client/dev_ops_pipeline"
+	"github.com/kubesphere/ks-devops-client-go/models"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// gcArchiver stores the bundles of the PipelineRuns before the garbage collector deletes them
+type gcArchiver interface {
+	store(ctx context.Context, key string, data []byte) error
+}
+
+// newGCArchiver creates an archiver by the target, it's a local directory or s3://bucket/prefix
+func newGCArchiver(target, s3Endpoint, s3Region string) (archiver gcArchiver, err error) {
+	if !strings.HasPrefix(target, "s3://") {
+		archiver = &dirArchiver{dir: target}
+		return
+	}
+
+	var targetURL *url.URL
+	if targetURL, err = url.Parse(target); err != nil {
+		return
+	}
+	if targetURL.Host == "" {
+		err = fmt.Errorf("the bucket is missing in the archive target: %s", target)
+		return
+	}
+
+	cfg := aws.Config{Region: aws.String(s3Region)}
+	if s3Endpoint != "" {
+		// the S3 compatible storages, such as MinIO, usually don't support the virtual hosted-style
+		cfg.Endpoint = aws.String(s3Endpoint)
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
+	var sess *session.Session
+	if sess, err = session.NewSessionWithOptions(session.Options{Config: cfg}); err == nil {
+		archiver = &s3Archiver{
+			client: s3.New(sess),
+			bucket: targetURL.Host,
+			prefix: strings.Trim(targetURL.Path, "/"),
+		}
+	}
+	return
+}
+
+type dirArchiver struct {
+	dir string
+}
+
+func (a *dirArchiver) store(_ context.Context, key string, data []byte) (err error) {
+	file := filepath.Join(a.dir, filepath.FromSlash(key))
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err == nil {
+		err = os.WriteFile(file, data, 0644)
+	}
+	return
+}
+
+type s3Archiver struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+func (a *s3Archiver) store(ctx context.Context, key string, data []byte) (err error) {
+	_, err = a.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(a.bucket),
+		Key:         aws.String(path.Join(a.prefix, key)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/gzip"),
+	})
+	return
+}
+
+// archivePipelineRun stores the YAML, log, stage nodes and artifacts of a PipelineRun as a tar.gz bundle,
+// the log, nodes and artifacts are fetched from the devops apiserver if the PipelineRun has a Jenkins run ID.
+// The artifacts, such as the test reports, are stored in the artifacts directory of the bundle.
+func (o *gcOption) archivePipelineRun(ctx context.Context, pipelineRun *unstructured.Unstructured) (err error) {
+	if o.archiver == nil {
+		return
+	}
+
+	ns, pipeline := pipelineRun.GetNamespace(), getPipelineRunPipeline(pipelineRun)
+	files := map[string][]byte{}
+	if files["pipelinerun.yaml"], err = yaml.Marshal(pipelineRun.Object); err != nil {
+		return
+	}
+
+	if runID := pipelineRun.GetAnnotations()[option.PipelinerunIdAnnotationKey]; runID != "" && o.devopsClient != nil {
+		branch := getPipelineRunBranch(pipelineRun)
+		if files["log.txt"], err = o.getRunFullLog(ctx, ns, pipeline, branch, runID); err != nil {
+			err = fmt.Errorf("failed to get the log, error: %v", err)
+			return
+		}
+		if files["nodes.json"], err = o.getRunNodes(ctx, ns, pipeline, branch, runID); err != nil {
+			err = fmt.Errorf("failed to get the nodes, error: %v", err)
+			return
+		}
+
+		var artifacts []*models.DevopsArtifacts
+		if artifacts, err = o.getRunArtifacts(ctx, ns, pipeline, branch, runID); err != nil {
+			err = fmt.Errorf("failed to get the artifacts, error: %v", err)
+			return
+		}
+		for _, artifact := range artifacts {
+			buf := bytes.NewBuffer(nil)
+			if err = o.downloadArtifact(ctx, buf, ns, pipelineRun.GetName(), artifact.Path); err != nil {
+				err = fmt.Errorf("failed to download the artifact %s, error: %v", artifact.Path, err)
+				return
+			}
+			// keep the entries inside the artifacts directory
+			files[path.Join("artifacts", path.Clean("/"+artifact.Path))] = buf.Bytes()
+		}
+	}
+
+	var data []byte
+	if data, err = newTarGzBundle(files); err == nil {
+		err = o.archiver.store(ctx, path.Join(ns, pipeline, pipelineRun.GetName()+".tar.gz"), data)
+	}
+	return
+}
+
+// getRunFullLog returns the whole log of a Jenkins run
+func (o *devopsAPIOption) getRunFullLog(ctx context.Context, ns, pipeline, branch, runID string) (data []byte, err error) {
+	buf := bytes.NewBuffer(nil)
+	var chunk *runLogChunk
+	for start := int64(0); ; start = chunk.next {
+		if chunk, err = o.writeRunLog(ctx, buf, ns, pipeline, branch, runID, start); err != nil {
+			return
+		}
+		if !chunk.more || chunk.next <= start {
+			break
+		}
+	}
+	data = buf.Bytes()
+	return
+}
+
+// getRunNodes returns the details of the stages and steps of a Jenkins run
+func (o *devopsAPIOption) getRunNodes(ctx context.Context, ns, pipeline, branch, runID string) (data []byte, err error) {
+	var result interface{}
+	if branch != "" {
+		var resp *dev_ops_pipeline.GetBranchNodesDetailOK
+		if resp, err = o.devopsClient.DevOpsPipeline.GetBranchNodesDetail(&dev_ops_pipeline.GetBranchNodesDetailParams{
+			Branch:   branch,
+			Devops:   ns,
+			Pipeline: pipeline,
+			Run:      runID,
+			Context:  ctx,
+		}); err == nil {
+			result = resp.GetPayload()
+		}
+	} else {
+		var resp *dev_ops_pipeline.GetNodesDetailOK
+		if resp, err = o.devopsClient.DevOpsPipeline.GetNodesDetail(&dev_ops_pipeline.GetNodesDetailParams{
+			Devops:   ns,
+			Pipeline: pipeline,
+			Run:      runID,
+			Context:  ctx,
+		}); err == nil {
+			result = resp.GetPayload()
+		}
+	}
+	if err == nil {
+		data, err = json.MarshalIndent(result, "", "  ")
+	}
+	return
+}
+
+// newTarGzBundle returns a tar.gz bundle which contains the given files, the well-known ones come first
+func newTarGzBundle(files map[string][]byte) (data []byte, err error) {
+	buf := bytes.NewBuffer(nil)
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	names := []string{"pipelinerun.yaml", "log.txt", "nodes.json"}
+	var others []string
+	for name := range files {
+		if name != names[0] && name != names[1] && name != names[2] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	now := time.Now()
+	for _, name := range names {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err = tarWriter.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}); err != nil {
+			return
+		}
+		if _, err = tarWriter.Write(content); err != nil {
+			return
+		}
+	}
+
+	if err = tarWriter.Close(); err == nil {
+		if err = gzipWriter.Close(); err == nil {
+			data = buf.Bytes()
+		}
+	}
+	return
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGCArchive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/runs/1/log":
+			_, _ = w.Write([]byte("hello"))
+		case "/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/runs/1/nodesdetail":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"displayName":"build","result":"SUCCESS"}]`))
+		case "/kapis/devops.kubesphere.io/v1alpha2/namespaces/ns/pipelines/pip/runs/1/artifacts":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"downloadable":true,"name":"report.xml","path":"target/report.xml"},
+{"downloadable":false,"name":"pipeline.log","path":"pipeline.log"}]`))
+		case "/kapis/devops.kubesphere.io/v1alpha3/namespaces/ns/pipelineruns/run-1/artifacts/download":
+			assert.Equal(t, "target/report.xml", r.URL.Query().Get("filename"))
+			_, _ = w.Write([]byte("<testsuite/>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	archived := newFakeCompletedRun("run-1", option.PipelinerunPhaseFailed, "a", now.Add(-2*time.Hour), time.Minute)
+	archived.SetAnnotations(map[string]string{option.PipelinerunIdAnnotationKey: "1"})
+	client := newFakeDynamicClient(newFakeGCPipeline("ns", "pip", "7", "1"), archived,
+		newFakeCompletedRun("run-2", option.PipelinerunPhaseFailed, "a", now.Add(-time.Hour), time.Minute))
+
+	dir := t.TempDir()
+	cmd := newGCCmd(client)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--archive-to", dir,
+		"--devops-api-host", strings.TrimPrefix(server.URL, "http://")})
+	assert.Nil(t, cmd.Execute())
+
+	data, err := os.ReadFile(filepath.Join(dir, "ns", "pip", "run-1.tar.gz"))
+	assert.Nil(t, err)
+	files := readTarGzBundle(t, data)
+	assert.Contains(t, files["pipelinerun.yaml"], "name: run-1")
+	assert.Equal(t, "hello", files["log.txt"])
+	assert.Contains(t, files["nodes.json"], "build")
+	assert.Equal(t, "<testsuite/>", files["artifacts/target/report.xml"])
+	assert.Len(t, files, 4)
+
+	list, err := client.Resource(types.GetPipelineRunSchema()).Namespace("ns").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, list.Items, 1)
+}
+
+func TestS3Archiver(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "fake")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "fake")
+
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		data, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+		objects[r.URL.Path] = data
+	}))
+	defer server.Close()
+
+	archiver, err := newGCArchiver("s3://bucket/prefix/", server.URL, "us-east-1")
+	assert.Nil(t, err)
+	assert.Nil(t, archiver.store(context.TODO(), "ns/pip/run-1.tar.gz", []byte("data")))
+	assert.Equal(t, map[string][]byte{"/bucket/prefix/ns/pip/run-1.tar.gz": []byte("data")}, objects)
+
+	_, err = newGCArchiver("s3:///prefix", "", "us-east-1")
+	assert.NotNil(t, err)
+}
+
+func readTarGzBundle(t *testing.T, data []byte) (files map[string]string) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	assert.Nil(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	files = map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		content, err := io.ReadAll(tarReader)
+		assert.Nil(t, err)
+		files[header.Name] = string(content)
+	}
+	return
+}
@@ -0,0 +1,177 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	gcDefaultNamespace = "kubesphere-devops-system"
+	gcLeaseName        = "ks-pipeline-gc"
+)
+
+// gcMetrics holds the Prometheus metrics of the garbage collector
+var gcMetrics = newGCMetricSet()
+
+type gcMetricSet struct {
+	registry     *prometheus.Registry
+	deleted      *prometheus.CounterVec
+	aborted      *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	loopDuration prometheus.Histogram
+}
+
+func newGCMetricSet() (metrics *gcMetricSet) {
+	metrics = &gcMetricSet{
+		registry: prometheus.NewRegistry(),
+		deleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ks_pipeline_gc_pipelineruns_deleted_total",
+			Help: "The number of PipelineRuns which are deleted by the garbage collector",
+		}, []string{"namespace"}),
+		aborted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ks_pipeline_gc_pipelineruns_aborted_total",
+			Help: "The number of PipelineRuns which are aborted by the garbage collector",
+		}, []string{"namespace"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ks_pipeline_gc_errors_total",
+			Help: "The number of errors which happen in the garbage collector",
+		}, []string{"namespace"}),
+		loopDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ks_pipeline_gc_loop_duration_seconds",
+			Help:    "The duration of a pass of the garbage collector",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		}),
+	}
+	metrics.registry.MustRegister(metrics.deleted, metrics.aborted, metrics.errors, metrics.loopDuration)
+	return
+}
+
+// runDaemon runs the garbage collector continuously until it receives a termination signal
+func (o *gcOption) runDaemon(cmd *cobra.Command) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: o.metricsAddress, Handler: newGCHTTPHandler()}
+	go func() {
+		if serveErr := server.ListenAndServe(); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			log.Errorf("failed to serve the metrics, error: %v", serveErr)
+			stop()
+		}
+	}()
+	defer func() {
+		_ = server.Shutdown(context.Background())
+	}()
+
+	if !o.leaderElect {
+		o.gcLoop(ctx, cmd)
+		return
+	}
+
+	var clientset *kubernetes.Clientset
+	if clientset = common.GetClientset(cmd.Root().Context()); clientset == nil {
+		err = fmt.Errorf("failed to get the Kubernetes clientset for the leader election")
+		return
+	}
+
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		if identity, err = os.Hostname(); err != nil {
+			return
+		}
+	}
+
+	lost := false
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metav1.ObjectMeta{
+				Name:      gcLeaseName,
+				Namespace: o.leaseNamespace,
+			},
+			Client:     clientset.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		ReleaseOnCancel: true,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Infof("%s became the leader of the garbage collectors", identity)
+				o.gcLoop(ctx, cmd)
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() == nil {
+					lost = true
+				}
+			},
+		},
+	})
+	if lost {
+		err = fmt.Errorf("%s lost the leadership of the garbage collectors", identity)
+	}
+	return
+}
+
+// gcLoop runs a pass of the garbage collector for each interval until the context is done
+func (o *gcOption) gcLoop(ctx context.Context, cmd *cobra.Command) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		begin := time.Now()
+		if o.allNamespaces {
+			if err := o.getAllDevOpsNamespace(); err != nil {
+				log.Errorf("failed to get all DevOps project namespace, error: %+v", err)
+			}
+		}
+		if err := o.gcOnce(ctx, cmd); err != nil {
+			log.Errorf("the pass of the garbage collector failed, error: %v", err)
+		}
+		gcMetrics.loopDuration.Observe(time.Since(begin).Seconds())
+
+		// the report only belongs to one pass
+		o.reportLock.Lock()
+		o.reportItems = nil
+		o.reportLock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func newGCHTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gcMetrics.registry, promhttp.HandlerOpts{}))
+	healthz := func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}
+	mux.HandleFunc("/healthz", healthz)
+	mux.HandleFunc("/readyz", healthz)
+	return mux
+}
+
+func getEnvOrDefault(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
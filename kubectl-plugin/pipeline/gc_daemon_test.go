@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGCLoop(t *testing.T) {
+	now := time.Now()
+	client := newFakeDynamicClient(newFakeGCPipeline("ns", "pip", "7", "1"),
+		newFakeCompletedRun("run-1", option.PipelinerunPhaseSucceeded, "a", now.Add(-3*time.Hour), time.Minute),
+		newFakeCompletedRun("run-2", option.PipelinerunPhaseFailed, "a", now.Add(-2*time.Hour), time.Minute),
+		newFakeCompletedRun("run-3", option.PipelinerunPhaseSucceeded, "a", now.Add(-time.Hour), time.Minute))
+	opt := &gcOption{
+		client:               client,
+		PipelineCreateOption: option.PipelineCreateOption{Client: client},
+		namespaces:           []string{"ns"},
+		interval:             time.Hour,
+	}
+
+	deleted := testutil.ToFloat64(gcMetrics.deleted.WithLabelValues("ns"))
+	loops := getGCLoopCount(t)
+
+	// stop the loop after the first pass
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		opt.gcLoop(ctx, &cobra.Command{})
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		return getGCLoopCount(t) == loops+1
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, deleted+2, testutil.ToFloat64(gcMetrics.deleted.WithLabelValues("ns")))
+	assert.Equal(t, loops+1, getGCLoopCount(t))
+	assert.Empty(t, opt.reportItems)
+}
+
+func TestGCOnceWithCancelledContext(t *testing.T) {
+	now := time.Now()
+	client := newFakeDynamicClient(newFakeGCPipeline("ns", "pip", "7", "1"),
+		newFakeCompletedRun("run-1", option.PipelinerunPhaseSucceeded, "a", now.Add(-3*time.Hour), time.Minute),
+		newFakeCompletedRun("run-2", option.PipelinerunPhaseFailed, "a", now.Add(-2*time.Hour), time.Minute),
+		newFakeCompletedRun("run-3", option.PipelinerunPhaseSucceeded, "a", now.Add(-time.Hour), time.Minute))
+	opt := &gcOption{
+		client:               client,
+		PipelineCreateOption: option.PipelineCreateOption{Client: client},
+		namespaces:           []string{"ns"},
+	}
+	cmd := &cobra.Command{}
+	cmd.SetOut(bytes.NewBuffer(nil))
+	cmd.SetErr(bytes.NewBuffer(nil))
+
+	// the replica which lost the leadership must not delete anything
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := opt.gcOnce(ctx, cmd)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), context.Canceled.Error())
+
+	list, err := client.Resource(types.GetPipelineRunSchema()).Namespace("ns").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, list.Items, 3)
+}
+
+func getGCLoopCount(t *testing.T) uint64 {
+	families, err := gcMetrics.registry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() == "ks_pipeline_gc_loop_duration_seconds" {
+			return family.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	return 0
+}
+
+func TestGCHTTPHandler(t *testing.T) {
+	server := httptest.NewServer(newGCHTTPHandler())
+	defer server.Close()
+
+	for _, path := range []string{"/healthz", "/readyz", "/metrics"} {
+		resp, err := http.Get(server.URL + path)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		_ = resp.Body.Close()
+	}
+}
+
+func TestGCInstall(t *testing.T) {
+	cmd := newGCInstallCmd()
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--namespace", "gc", "--interval", "30m", "--", "--clean-pipelinerun", "--max-count", "20"})
+	assert.Nil(t, cmd.Execute())
+
+	docs := bytes.Split(buf.Bytes(), []byte("\n---\n"))
+	assert.Len(t, docs, 6)
+
+	deploy := map[string]interface{}{}
+	assert.Nil(t, yaml.Unmarshal(docs[5], &deploy))
+	assert.Equal(t, "Deployment", deploy["kind"])
+	assert.Equal(t, "gc", deploy["metadata"].(map[string]interface{})["namespace"])
+	containers := deploy["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+	assert.Equal(t, []interface{}{"pip", "gc", "--daemon", "--interval", "30m0s", "--clean-pipelinerun", "--max-count", "20"},
+		containers[0].(map[string]interface{})["args"])
+}
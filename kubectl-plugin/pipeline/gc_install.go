@@ -0,0 +1,162 @@
+package pipeline
+
+import (
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+	"github.com/spf13/cobra"
+)
+
+func newGCInstallCmd() (cmd *cobra.Command) {
+	opt := &gcInstallOption{}
+	cmd = &cobra.Command{
+		Use:   "install",
+		Short: "Output the manifests to run the garbage collector inside the cluster",
+		Long: `Output the manifests to run the garbage collector inside the cluster
+The manifests include the Deployment, ServiceAccount and RBAC. The extra arguments will be passed to the garbage collector.`,
+		Example: `ks pip gc install | kubectl apply -f -
+ks pip gc install --interval 30m -- --clean-pipelinerun --max-count 20`,
+		RunE: opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.Namespace, "namespace", "n", gcDefaultNamespace,
+		"The namespace to run the garbage collector")
+	flags.StringVarP(&opt.Image, "image", "", "kubespheredev/ks-tool:latest",
+		"The image which contains the ks command")
+	flags.DurationVarP(&opt.Interval, "interval", "", time.Hour,
+		"The interval between two passes of the garbage collector")
+	flags.Int32VarP(&opt.Replicas, "replicas", "", 1,
+		"The replicas of the garbage collector, only the leader works")
+	return
+}
+
+type gcInstallOption struct {
+	Namespace string
+	Image     string
+	Interval  time.Duration
+	Replicas  int32
+	Args      []string
+}
+
+func (o *gcInstallOption) runE(cmd *cobra.Command, args []string) (err error) {
+	o.Args = append([]string{"pip", "gc", "--daemon", "--interval", o.Interval.String()}, args...)
+
+	var tpl *template.Template
+	if tpl, err = template.New("gc").Funcs(sprig.TxtFuncMap()).Parse(gcInstallTemplate); err == nil {
+		err = tpl.Execute(cmd.OutOrStdout(), o)
+	}
+	return
+}
+
+var gcInstallTemplate = strings.TrimPrefix(`
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ks-pipeline-gc
+  namespace: {{.Namespace}}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: ks-pipeline-gc
+rules:
+- apiGroups: [""]
+  resources: ["namespaces"]
+  verbs: ["get", "list"]
+- apiGroups: ["devops.kubesphere.io"]
+  resources: ["pipelines", "devopsprojects"]
+  verbs: ["get", "list"]
+- apiGroups: ["devops.kubesphere.io"]
+  resources: ["pipelineruns"]
+  verbs: ["get", "list", "delete"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: ks-pipeline-gc
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: ks-pipeline-gc
+subjects:
+- kind: ServiceAccount
+  name: ks-pipeline-gc
+  namespace: {{.Namespace}}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: ks-pipeline-gc
+  namespace: {{.Namespace}}
+rules:
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["get", "create", "update"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: ks-pipeline-gc
+  namespace: {{.Namespace}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: ks-pipeline-gc
+subjects:
+- kind: ServiceAccount
+  name: ks-pipeline-gc
+  namespace: {{.Namespace}}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ks-pipeline-gc
+  namespace: {{.Namespace}}
+  labels:
+    app: ks-pipeline-gc
+spec:
+  replicas: {{.Replicas}}
+  selector:
+    matchLabels:
+      app: ks-pipeline-gc
+  template:
+    metadata:
+      labels:
+        app: ks-pipeline-gc
+      annotations:
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "8080"
+    spec:
+      serviceAccountName: ks-pipeline-gc
+      containers:
+      - name: gc
+        image: {{.Image}}
+        command: ["ks"]
+        args:
+        {{- range .Args}}
+        - {{. | quote}}
+        {{- end}}
+        env:
+        - name: POD_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        - name: POD_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        ports:
+        - name: metrics
+          containerPort: 8080
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: metrics
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: metrics
+`, "\n")
@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"sigs.k8s.io/yaml"
+)
+
+const outputFormatTable = "table"
+
+// the actions of the garbage collector
+const (
+	gcActionDelete = "delete"
+	gcActionAbort  = "abort"
+	gcActionKeep   = "keep"
+)
+
+// the reasons why a PipelineRun is deleted, aborted or kept
+const (
+	gcReasonNumToKeep      = "discarder num_to_keep"
+	gcReasonDaysToKeep     = "discarder days_to_keep"
+	gcReasonMaxCount       = "max-count"
+	gcReasonMaxAge         = "max-age"
+	gcReasonAgeToAbort     = "age-to-abort"
+	gcReasonLastSuccessful = "last-successful"
+	gcReasonLastStable     = "last-stable"
+	gcReasonBranchRemoved  = "branch removed from SCM"
+	gcReasonPinned         = "pinned"
+)
+
+// gcReportItem is an action of the garbage collector on a PipelineRun
+type gcReportItem struct {
+	Namespace   string `json:"namespace"`
+	Pipeline    string `json:"pipeline,omitempty"`
+	PipelineRun string `json:"pipelineRun"`
+	Action      string `json:"action"`
+	Reason      string `json:"reason"`
+	DryRun      bool   `json:"dryRun,omitempty"`
+}
+
+func (o *gcOption) addReportItem(ns, pipeline, pipelineRun, action, reason string) {
+	o.reportLock.Lock()
+	defer o.reportLock.Unlock()
+	o.reportItems = append(o.reportItems, gcReportItem{
+		Namespace:   ns,
+		Pipeline:    pipeline,
+		PipelineRun: pipelineRun,
+		Action:      action,
+		Reason:      reason,
+		DryRun:      o.dryRun,
+	})
+}
+
+// printReport prints the report in the given format, nothing will be printed if there is no format given
+func (o *gcOption) printReport(w io.Writer) (err error) {
+	o.reportLock.Lock()
+	defer o.reportLock.Unlock()
+
+	items := o.reportItems
+	if items == nil {
+		items = []gcReportItem{}
+	}
+	// the namespaces are processed concurrently, keep the order of the items in the same namespace
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Namespace < items[j].Namespace
+	})
+
+	switch o.report {
+	case outputFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(items)
+	case outputFormatYAML:
+		var data []byte
+		if data, err = yaml.Marshal(items); err == nil {
+			_, err = w.Write(data)
+		}
+	case outputFormatTable:
+		rows := make([][]string, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, []string{item.Namespace, common.EmptyAsNone(item.Pipeline), item.PipelineRun, item.Action, item.Reason})
+		}
+		if err = common.PrintTable(w, []string{"NAMESPACE", "PIPELINE", "PIPELINERUN", "ACTION", "REASON"}, rows); err == nil && o.dryRun {
+			_, err = fmt.Fprintln(w, "nothing was changed because of the dry run mode")
+		}
+	}
+	return
+}
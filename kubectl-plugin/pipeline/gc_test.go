@@ -1,14 +1,26 @@
 package pipeline
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestDescOrderWithCompletionTime(t *testing.T) {
@@ -163,13 +175,13 @@ func TestNeedToDelete(t *testing.T) {
 		completionTime: now.AddDate(0, 0, -1),
 	}
 	pipeline.pipelinerunList[2].completionTime = pipeline.pipelinerunList[2].completionTime.Add(10 * time.Minute)
-	deletingList := pipeline.needToDelete()
-	assert.EqualValues(t, []string{"run-0", "run-1"}, deletingList)
+	deletingList, _ := pipeline.needToDelete()
+	assert.EqualValues(t, []string{"run-0", "run-1"}, getGCPipelinerunNames(deletingList))
 
 	// case2: num_to_keep
 	pipeline.numToKeep = 1
-	deletingList = pipeline.needToDelete()
-	assert.EqualValues(t, []string{"run-0", "run-1", "run-2"}, deletingList)
+	deletingList, _ = pipeline.needToDelete()
+	assert.EqualValues(t, []string{"run-0", "run-1", "run-2"}, getGCPipelinerunNames(deletingList))
 
 	// case3: complex with un-completion and lastStable and lastSuccessful
 	// pipelinerunList: {
@@ -194,10 +206,350 @@ func TestNeedToDelete(t *testing.T) {
 	pipeline.pipelinerunList[4].phase = option.PipelinerunPhaseFailed // setup Phase to failed
 	pipeline.pipelinerunList[5].phase = option.PipelinerunPhaseFailed // setup Phase to failed
 	pipeline.pipelinerunList[6].phase = option.PipelinerunPhaseFailed // setup Phase to failed
-	deletingList = pipeline.needToDelete()
-	assert.EqualValues(t, []string{"run-0", "run-1", "run-4"}, deletingList)
+	deletingList, keepingList := pipeline.needToDelete()
+	assert.EqualValues(t, []string{"run-0", "run-1", "run-4"}, getGCPipelinerunNames(deletingList))
+	assert.EqualValues(t, []string{"run-3"}, getGCPipelinerunNames(keepingList))
+	assert.Equal(t, gcReasonNumToKeep, deletingList[0].reason)
+	assert.Equal(t, gcReasonLastSuccessful, keepingList[0].reason)
 
 	pipeline.daysToKeep = 2
-	deletingList = pipeline.needToDelete()
-	assert.EqualValues(t, []string{"run-0", "run-1", "run-4", "run-5"}, deletingList)
+	deletingList, _ = pipeline.needToDelete()
+	assert.EqualValues(t, []string{"run-0", "run-1", "run-4", "run-5"}, getGCPipelinerunNames(deletingList))
+	assert.Equal(t, gcReasonDaysToKeep, deletingList[3].reason)
+}
+
+func TestNeedToDeleteOfNoScmPipeline(t *testing.T) {
+	now := time.Now()
+	newRuns := func() []*gcPipelinerun {
+		return []*gcPipelinerun{
+			{name: "run-0", phase: option.PipelinerunPhaseSucceeded, completionTime: now.AddDate(0, 0, -3)},
+			{name: "run-1", phase: option.PipelinerunPhaseFailed, completionTime: now.AddDate(0, 0, -1)},
+		}
+	}
+	pipeline := &gcPipeline{pType: option.NoScmPipelineType}
+
+	// a non-positive num_to_keep deletes all but the last-successful and last-stable ones
+	for _, numToKeep := range []int{0, -1} {
+		pipeline.daysToKeep, pipeline.numToKeep, pipeline.pipelinerunList = 7, numToKeep, newRuns()
+		deletingList, keepingList := pipeline.needToDelete()
+		assert.Empty(t, deletingList)
+		assert.EqualValues(t, []string{"run-0", "run-1"}, getGCPipelinerunNames(keepingList))
+	}
+
+	// a non-positive days_to_keep means no age limit
+	for _, daysToKeep := range []int{0, -1} {
+		pipeline.daysToKeep, pipeline.numToKeep, pipeline.pipelinerunList = daysToKeep, 5, newRuns()
+		deletingList, keepingList := pipeline.needToDelete()
+		assert.Empty(t, deletingList)
+		assert.Empty(t, keepingList)
+	}
+
+	// the last-successful one is not kept from days_to_keep
+	pipeline.daysToKeep, pipeline.numToKeep, pipeline.pipelinerunList = 2, 5, newRuns()
+	deletingList, keepingList := pipeline.needToDelete()
+	assert.EqualValues(t, []string{"run-0"}, getGCPipelinerunNames(deletingList))
+	assert.Equal(t, gcReasonDaysToKeep, deletingList[0].reason)
+	assert.Empty(t, keepingList)
+
+	// the pinned one is always kept
+	pipeline.daysToKeep, pipeline.numToKeep, pipeline.pipelinerunList = 2, 5, newRuns()
+	pipeline.pipelinerunList[0].keep = true
+	deletingList, keepingList = pipeline.needToDelete()
+	assert.Empty(t, deletingList)
+	assert.EqualValues(t, []string{"run-0"}, getGCPipelinerunNames(keepingList))
+	assert.Equal(t, gcReasonPinned, keepingList[0].reason)
+}
+
+func getGCPipelinerunNames(runs []*gcPipelinerun) (names []string) {
+	for _, run := range runs {
+		names = append(names, run.name)
+	}
+	return
+}
+
+func newFakeGCPipeline(ns, name, daysToKeep, numToKeep string) *unstructured.Unstructured {
+	pip := newFakePipeline(ns, name, "")
+	_ = unstructured.SetNestedStringMap(pip.Object, map[string]string{
+		"days_to_keep": daysToKeep,
+		"num_to_keep":  numToKeep,
+	}, "spec", "pipeline", "discarder")
+	return pip
+}
+
+func TestGCDryRun(t *testing.T) {
+	now := time.Now()
+	client := newFakeDynamicClient(newFakeGCPipeline("ns", "pip", "7", "1"),
+		newFakeCompletedRun("run-1", option.PipelinerunPhaseSucceeded, "a", now.Add(-3*time.Hour), time.Minute),
+		newFakeCompletedRun("run-2", option.PipelinerunPhaseSucceeded, "a", now.Add(-2*time.Hour), time.Minute),
+		newFakeCompletedRun("run-3", option.PipelinerunPhaseFailed, "a", now.Add(-time.Hour), time.Minute))
+
+	cmd := newGCCmd(client)
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--dry-run", "--report", "json"})
+	assert.Nil(t, cmd.Execute())
+
+	var report []gcReportItem
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, []gcReportItem{{
+		Namespace: "ns", Pipeline: "pip", PipelineRun: "run-2", Action: gcActionKeep, Reason: gcReasonLastSuccessful, DryRun: true,
+	}, {
+		Namespace: "ns", Pipeline: "pip", PipelineRun: "run-3", Action: gcActionKeep, Reason: gcReasonLastStable, DryRun: true,
+	}, {
+		Namespace: "ns", Pipeline: "pip", PipelineRun: "run-1", Action: gcActionDelete, Reason: gcReasonNumToKeep, DryRun: true,
+	}}, report)
+
+	// nothing was deleted
+	list, err := client.Resource(types.GetPipelineRunSchema()).Namespace("ns").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, list.Items, 3)
+
+	// table is the default report format of the dry run mode
+	cmd = newGCCmd(client)
+	buf.Reset()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--dry-run"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "NAMESPACE")
+	assert.Contains(t, buf.String(), "discarder num_to_keep")
+
+	cmd = newGCCmd(client)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--report", "fake"})
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	assert.NotNil(t, cmd.Execute())
+}
+
+func newFakeBranchRun(name, branch, phase string, start time.Time) *unstructured.Unstructured {
+	run := newFakeCompletedRun(name, phase, "a", start, time.Minute)
+	_ = unstructured.SetNestedField(run.Object, option.MultiBranchPipelineType, "spec", "pipelineSpec", "type")
+	_ = unstructured.SetNestedField(run.Object, branch, "spec", "scm", "refName")
+	return run
+}
+
+func TestGCMultiBranch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/kapis/devops.kubesphere.io/v1alpha3/namespaces/ns/pipelines/pip/branches", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[{"name":"main"},{"name":"feature%2Fa","rawName":"feature/a"}],"totalItems":2}`))
+	}))
+	defer server.Close()
+
+	pip := newFakePipeline("ns", "pip", "")
+	pip.Object["spec"] = map[string]interface{}{
+		"type": option.MultiBranchPipelineType,
+		"multi_branch_pipeline": map[string]interface{}{
+			"discarder": map[string]interface{}{"days_to_keep": "-1", "num_to_keep": "1"},
+		},
+	}
+	now := time.Now()
+	client := newFakeDynamicClient(pip,
+		newFakeBranchRun("main-1", "main", option.PipelinerunPhaseSucceeded, now.Add(-5*time.Hour)),
+		newFakeBranchRun("main-2", "main", option.PipelinerunPhaseFailed, now.Add(-4*time.Hour)),
+		newFakeBranchRun("main-3", "main", option.PipelinerunPhaseSucceeded, now.Add(-3*time.Hour)),
+		newFakeBranchRun("feature-1", "feature/a", option.PipelinerunPhaseSucceeded, now.Add(-2*time.Hour)),
+		newFakeBranchRun("removed-1", "removed", option.PipelinerunPhaseSucceeded, now.Add(-time.Hour)))
+
+	cmd := newGCCmd(client)
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--dry-run", "--report", "json",
+		"--devops-api-host", strings.TrimPrefix(server.URL, "http://")})
+	assert.Nil(t, cmd.Execute())
+
+	var report []gcReportItem
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &report))
+	actions := map[string]string{}
+	for _, item := range report {
+		actions[item.PipelineRun] = fmt.Sprintf("%s: %s", item.Action, item.Reason)
+	}
+	assert.Equal(t, map[string]string{
+		"main-1":    "delete: discarder num_to_keep",
+		"main-2":    "delete: discarder num_to_keep",
+		"removed-1": "delete: branch removed from SCM",
+	}, actions)
+}
+
+func TestGCAnnotations(t *testing.T) {
+	project := newFakeDevOpsProject("ns", "", "ws")
+	project.SetAnnotations(map[string]string{gcMaxCountAnnotationKey: "1"})
+	pip := newFakeGCPipeline("ns", "pip", "7", "10")
+	pip.SetAnnotations(map[string]string{gcMaxCountAnnotationKey: "1", gcMaxAgeAnnotationKey: "1h"})
+
+	now := time.Now()
+	pinned := newFakeCompletedRun("run-1", option.PipelinerunPhaseFailed, "a", now.Add(-5*time.Hour), time.Minute)
+	pinned.SetAnnotations(map[string]string{gcKeepAnnotationKey: "true"})
+	client := newFakeDynamicClient(project, pip, pinned,
+		newFakeCompletedRun("run-2", option.PipelinerunPhaseFailed, "a", now.Add(-4*time.Hour), time.Minute),
+		newFakeCompletedRun("run-3", option.PipelinerunPhaseSucceeded, "a", now.Add(-3*time.Hour), time.Minute),
+		newFakeCompletedRun("run-4", option.PipelinerunPhaseFailed, "a", now.Add(-30*time.Minute), time.Minute))
+
+	// the discarder is overridden by the annotations of the Pipeline
+	cmd := newGCCmd(client)
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--dry-run", "--report", "json"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, map[string]string{
+		"run-1": "keep: pinned",
+		"run-2": "delete: discarder num_to_keep",
+		"run-3": "keep: last-successful",
+		"run-4": "keep: last-stable",
+	}, getGCReportActions(t, buf.Bytes()))
+
+	// the max-count is overridden by the annotations of the DevOpsProject
+	cmd = newGCCmd(client)
+	buf.Reset()
+	cmd.SetOut(buf)
+	pip.SetAnnotations(nil)
+	_, err := client.Resource(types.GetPipelineSchema()).Namespace("ns").Update(context.TODO(), pip, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--dry-run", "--report", "json", "--clean-pipelinerun", "--condition", conditionIgnore})
+	assert.Nil(t, cmd.Execute())
+	actions := getGCReportActions(t, buf.Bytes())
+	assert.Equal(t, "keep: pinned", actions["run-1"])
+	assert.Equal(t, "delete: max-count", actions["run-2"])
+	assert.Equal(t, "delete: max-count", actions["run-3"])
+	assert.NotContains(t, actions, "run-4")
+}
+
+func TestGCPipelinePolicies(t *testing.T) {
+	project := newFakeDevOpsProject("ns", "", "ws")
+	project.SetAnnotations(map[string]string{gcMaxCountAnnotationKey: "1", gcMaxAgeAnnotationKey: "fake"})
+	pip := newFakeGCPipeline("ns", "pip", "7", "10")
+	pip.SetAnnotations(map[string]string{gcMaxCountAnnotationKey: "3"})
+	other := newFakeGCPipeline("ns", "other", "7", "10")
+	other.SetAnnotations(map[string]string{gcMaxCountAnnotationKey: "fake"})
+
+	now := time.Now()
+	objects := []runtime.Object{project, pip, other}
+	for i := 1; i <= 3; i++ {
+		start := now.Add(-time.Duration(10-i) * time.Hour)
+		objects = append(objects, newFakeCompletedRun(fmt.Sprintf("pip-%d", i), option.PipelinerunPhaseSucceeded, "a", start, time.Minute))
+		otherRun := newFakeCompletedRun(fmt.Sprintf("other-%d", i), option.PipelinerunPhaseSucceeded, "a", start, time.Minute)
+		otherRun.SetLabels(map[string]string{option.PipelinerunOwnerLabelKey: "other"})
+		objects = append(objects, otherRun)
+	}
+	client := newFakeDynamicClient(objects...)
+
+	logs := bytes.NewBuffer(nil)
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	// the PipelineRuns of the Pipeline which has its own max-count are counted separately
+	cmd := newGCCmd(client)
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--dry-run", "--report", "json", "--clean-pipelinerun", "--condition", conditionIgnore})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, map[string]string{
+		"other-1": "delete: max-count",
+		"other-2": "delete: max-count",
+	}, getGCReportActions(t, buf.Bytes()))
+
+	// the invalid annotations fall back to the flags with a warning
+	assert.Contains(t, logs.String(), "invalid annotation devops.kubesphere.io/gc-max-age of DevOpsProject ns")
+	assert.Contains(t, logs.String(), "invalid annotation devops.kubesphere.io/gc-max-count of Pipeline ns/other")
+}
+
+func TestGCPipelinePolicyFallback(t *testing.T) {
+	project := newFakeDevOpsProject("ns", "", "ws")
+	project.SetAnnotations(map[string]string{gcMaxCountAnnotationKey: "1"})
+	// the Pipelines have no discarder
+	pip := newFakePipeline("ns", "pip", "")
+	pip.SetAnnotations(map[string]string{gcMaxCountAnnotationKey: "2"})
+	other := newFakePipeline("ns", "other", "")
+
+	now := time.Now()
+	objects := []runtime.Object{project, pip, other}
+	for i := 1; i <= 4; i++ {
+		phase := option.PipelinerunPhaseSucceeded
+		if i > 2 {
+			phase = option.PipelinerunPhaseFailed
+		}
+		start := now.Add(-time.Duration(10-i) * time.Hour)
+		objects = append(objects, newFakeCompletedRun(fmt.Sprintf("pip-%d", i), phase, "a", start, time.Minute))
+		otherRun := newFakeCompletedRun(fmt.Sprintf("other-%d", i), phase, "a", start, time.Minute)
+		otherRun.SetLabels(map[string]string{option.PipelinerunOwnerLabelKey: "other"})
+		objects = append(objects, otherRun)
+	}
+	client := newFakeDynamicClient(objects...)
+
+	// the max-count annotation keeps the newest runs without an age limit, the max-age is taken from the
+	// namespace policy. The Pipeline without any annotations takes the policy of the DevOpsProject.
+	cmd := newGCCmd(client)
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--namespaces", "ns", "--dry-run", "--report", "json"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, map[string]string{
+		"pip-1":   "delete: discarder num_to_keep",
+		"pip-2":   "keep: last-successful",
+		"pip-3":   "delete: discarder num_to_keep",
+		"other-1": "delete: discarder num_to_keep",
+		"other-2": "keep: last-successful",
+		"other-3": "delete: discarder num_to_keep",
+		"other-4": "keep: last-stable",
+	}, getGCReportActions(t, buf.Bytes()))
+}
+
+func getGCReportActions(t *testing.T, data []byte) (actions map[string]string) {
+	var report []gcReportItem
+	assert.Nil(t, json.Unmarshal(data, &report))
+	actions = map[string]string{}
+	for _, item := range report {
+		actions[item.PipelineRun] = fmt.Sprintf("%s: %s", item.Action, item.Reason)
+	}
+	return
+}
+
+func TestGCConcurrency(t *testing.T) {
+	now := time.Now()
+	var objects []runtime.Object
+	for _, ns := range []string{"ns-1", "ns-2", "broken"} {
+		objects = append(objects, newFakeGCPipeline(ns, "pip", "7", "1"))
+		for i := 1; i <= 2; i++ {
+			run := newFakeCompletedRun(fmt.Sprintf("run-%d", i), option.PipelinerunPhaseFailed, "a",
+				now.Add(-time.Duration(3-i)*time.Hour), time.Minute)
+			run.SetNamespace(ns)
+			objects = append(objects, run)
+		}
+	}
+	client := newFakeDynamicClient(objects...)
+	client.PrependReactor("list", "pipelines", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "broken" {
+			return true, nil, fmt.Errorf("fake error")
+		}
+		return false, nil, nil
+	})
+
+	cmd := newGCCmd(client)
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+	cmd.SetErr(bytes.NewBuffer(nil))
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"--namespaces", "ns-1", "--namespaces", "broken", "--namespaces", "ns-2",
+		"--concurrency", "2", "--qps", "100", "--report", "json"})
+	err := cmd.Execute()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "namespace broken")
+	assert.NotContains(t, err.Error(), "ns-1")
+
+	// the broken namespace does not stop others
+	var report []gcReportItem
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &report))
+	var namespaces []string
+	for _, item := range report {
+		namespaces = append(namespaces, item.Namespace)
+	}
+	assert.Equal(t, []string{"ns-1", "ns-2"}, namespaces)
+	for _, ns := range []string{"ns-1", "ns-2"} {
+		list, err := client.Resource(types.GetPipelineRunSchema()).Namespace(ns).List(context.TODO(), metav1.ListOptions{})
+		assert.Nil(t, err)
+		assert.Len(t, list.Items, 1)
+	}
+
+	cmd = newGCCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"--namespaces", "ns-1", "--concurrency", "0"})
+	assert.NotNil(t, cmd.Execute())
 }
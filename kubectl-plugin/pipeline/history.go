@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/jenkinsfile"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+)
+
+func newPipelineHistoryCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineHistoryOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:     "history",
+		Aliases: []string{"his"},
+		Short:   "List the PipelineRuns of a Pipeline",
+		Example: `ks pip history devops-ns my-pipeline --phase Failed --since 24h
+ks pip history devops-ns my-pipeline --limit 5 -o wide`,
+		Args:    cobra.MaximumNArgs(2),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVarP(&opt.phases, "phase", "", nil,
+		"Only list the PipelineRuns in these phases, such as: Running, Succeeded, Failed, Cancelled")
+	flags.DurationVarP(&opt.since, "since", "", 0,
+		"Only list the PipelineRuns which were created in this duration, such as: 24h")
+	flags.IntVarP(&opt.limit, "limit", "", 0,
+		"The maximum number of PipelineRuns to list, zero means no limit")
+	flags.StringVarP(&opt.output, "output", "o", "",
+		"The output format, supported formats: json, yaml, wide")
+
+	_ = cmd.RegisterFlagCompletionFunc("phase", common.ArrayCompletion(option.PipelinerunPhaseRunning,
+		option.PipelinerunPhaseSucceeded, option.PipelinerunPhaseFailed, option.PipelinerunPhaseCancelled))
+	_ = cmd.RegisterFlagCompletionFunc("output", common.ArrayCompletion(outputFormatJSON, outputFormatYAML, outputFormatWide))
+	return
+}
+
+type pipelineHistoryOption struct {
+	phases []string
+	since  time.Duration
+	limit  int
+	output string
+
+	// inner fields
+	client    dynamic.Interface
+	namespace string
+	pipeline  string
+	passwords map[string]bool
+}
+
+func (o *pipelineHistoryOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	if o.namespace, err = getNamespace(o.client, args); err != nil {
+		return
+	}
+
+	if len(args) > 1 {
+		o.pipeline = args[1]
+	}
+	o.pipeline, err = choosePipeline(o.client, o.namespace, o.pipeline)
+	return
+}
+
+func (o *pipelineHistoryOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	var items []unstructured.Unstructured
+	if items, err = getPipelineRunList(context.TODO(), o.client, o.namespace, o.pipeline); err != nil {
+		return
+	}
+	items = o.filter(items)
+
+	switch o.output {
+	case outputFormatJSON, outputFormatYAML:
+		err = printObjects(cmd.OutOrStdout(), o.output, items)
+	case outputFormatWide, "":
+		if o.output == outputFormatWide {
+			o.passwords = o.getPasswordParameters()
+		}
+		err = common.PrintTable(cmd.OutOrStdout(), o.getHeaders(), o.getRows(items))
+	default:
+		err = fmt.Errorf("not supported output format: %s", o.output)
+	}
+	return
+}
+
+// filter returns the PipelineRuns which match the phases, since and limit options
+func (o *pipelineHistoryOption) filter(items []unstructured.Unstructured) (result []unstructured.Unstructured) {
+	result = make([]unstructured.Unstructured, 0, len(items))
+	for i := range items {
+		item := items[i]
+		if o.limit > 0 && len(result) >= o.limit {
+			break
+		}
+
+		if len(o.phases) > 0 && !containsIgnoreCase(o.phases, getPipelineRunPhase(&item)) {
+			continue
+		}
+		if o.since > 0 && item.GetCreationTimestamp().Time.Before(time.Now().Add(-o.since)) {
+			continue
+		}
+		result = append(result, item)
+	}
+	return
+}
+
+// getPasswordParameters returns the names of the password parameters which are declared by the Pipeline
+func (o *pipelineHistoryOption) getPasswordParameters() (passwords map[string]bool) {
+	passwords = map[string]bool{}
+	pip, err := getPipeline(o.pipeline, o.namespace, o.client)
+	if err != nil {
+		return
+	}
+
+	declared, _ := getPipelineParameters(pip)
+	for _, param := range declared {
+		if param.Type == jenkinsfile.ParameterTypePassword {
+			passwords[param.Name] = true
+		}
+	}
+	return
+}
+
+func (o *pipelineHistoryOption) getHeaders() (headers []string) {
+	headers = []string{"NAME", "ID", "PHASE", "BRANCH", "CAUSE", "START", "DURATION"}
+	if o.output == outputFormatWide {
+		headers = append(headers, "COMMIT", "PARAMETERS")
+	}
+	return
+}
+
+func (o *pipelineHistoryOption) getRows(items []unstructured.Unstructured) (rows [][]string) {
+	for i := range items {
+		item := &items[i]
+		row := []string{
+			item.GetName(),
+			common.EmptyAsNone(item.GetAnnotations()[option.PipelinerunIdAnnotationKey]),
+			common.EmptyAsNone(getPipelineRunPhase(item)),
+			common.EmptyAsNone(getPipelineRunBranch(item)),
+			common.EmptyAsNone(getPipelineRunCause(item)),
+			getPipelineRunStartTime(item).Local().Format("2006-01-02 15:04:05"),
+			duration.HumanDuration(getPipelineRunDuration(item)),
+		}
+		if o.output == outputFormatWide {
+			row = append(row, common.EmptyAsNone(getJenkinsRunStatus(item).CommitID),
+				common.EmptyAsNone(strings.Join(getPipelineRunParameters(item, o.passwords), ",")))
+		}
+		rows = append(rows, row)
+	}
+	return
+}
+
+func containsIgnoreCase(items []string, target string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPipelineHistory(t *testing.T) {
+	now := time.Now()
+	running := newFakePipelineRun("run-3", "pip", now)
+	_ = unstructured.SetNestedField(running.Object, option.PipelinerunPhaseRunning, "status", "phase")
+	failed := newFakePipelineRun("run-2", "pip", now.Add(-time.Hour))
+	failed.SetAnnotations(map[string]string{
+		option.PipelinerunIdAnnotationKey:     "2",
+		option.PipelinerunStatusAnnotationKey: `{"commitId":"abc","causes":[{"shortDescription":"Started by user admin"}]}`,
+	})
+	_ = unstructured.SetNestedField(failed.Object, option.PipelinerunPhaseFailed, "status", "phase")
+	_ = unstructured.SetNestedField(failed.Object, now.Add(-time.Hour).Format(time.RFC3339), "status", "startTime")
+	_ = unstructured.SetNestedField(failed.Object, now.Add(-50*time.Minute).Format(time.RFC3339), "status", "completionTime")
+	_ = unstructured.SetNestedSlice(failed.Object, []interface{}{
+		map[string]interface{}{"name": "user", "value": "admin"},
+		map[string]interface{}{"name": "token", "value": "secret"},
+	}, "spec", "parameters")
+	old := newFakePipelineRun("run-1", "pip", now.Add(-48*time.Hour))
+	_ = unstructured.SetNestedField(old.Object, option.PipelinerunPhaseFailed, "status", "phase")
+
+	opt := &pipelineHistoryOption{
+		client: newFakeDynamicClient(old, running, failed, newFakePipeline("ns", "pip",
+			`pipeline { parameters { password(name: 'token', defaultValue: '') } }`)),
+		namespace: "ns",
+		pipeline:  "pip",
+	}
+	cmd := &cobra.Command{}
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+
+	assert.Nil(t, opt.runE(cmd, nil))
+	assert.Equal(t, `NAME    ID       PHASE     BRANCH   CAUSE                   START                 DURATION
+run-3   <none>   Running   <none>   <none>                  `+now.Format("2006-01-02 15:04:05")+`   0s
+run-2   2        Failed    <none>   Started by user admin   `+now.Add(-time.Hour).Format("2006-01-02 15:04:05")+`   10m
+run-1   <none>   Failed    <none>   <none>                  `+now.Add(-48*time.Hour).Format("2006-01-02 15:04:05")+`   2d
+`, buf.String())
+
+	opt.phases = []string{"failed"}
+	opt.since = 24 * time.Hour
+	opt.output = outputFormatWide
+	buf.Reset()
+	assert.Nil(t, opt.runE(cmd, nil))
+	assert.Contains(t, buf.String(), "run-2")
+	assert.Contains(t, buf.String(), "abc")
+	assert.Contains(t, buf.String(), "user=admin,token=******")
+	assert.NotContains(t, buf.String(), "secret")
+	assert.NotContains(t, buf.String(), "run-1")
+	assert.NotContains(t, buf.String(), "run-3")
+
+	opt.phases = nil
+	opt.since = 0
+	opt.limit = 1
+	opt.output = outputFormatYAML
+	buf.Reset()
+	assert.Nil(t, opt.runE(cmd, nil))
+	assert.Contains(t, buf.String(), "name: run-3")
+	assert.NotContains(t, buf.String(), "name: run-2")
+
+	opt.output = "fake"
+	assert.NotNil(t, opt.runE(cmd, nil))
+}
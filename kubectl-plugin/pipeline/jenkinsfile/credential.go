@@ -0,0 +1,39 @@
+package jenkinsfile
+
+import (
+	"sort"
+	"strings"
+)
+
+// ParseCredentialIDs returns the IDs of credentials which are referenced in a Jenkinsfile,
+// such as: credentialsId: 'id' or credentials('id'). The dynamic ones will be ignored.
+func ParseCredentialIDs(text string) (ids []string, err error) {
+	var tokens []Token
+	if tokens, err = Tokenize(text); err != nil {
+		return
+	}
+
+	found := map[string]bool{}
+	for i := 0; i+2 < len(tokens); i++ {
+		var id Token
+		switch {
+		case tokens[i].Kind == Ident && tokens[i].Text == "credentialsId" && tokens[i+1].Is(":"):
+			id = tokens[i+2]
+		case tokens[i].Kind == Ident && tokens[i].Text == "credentials" && tokens[i+1].Is("("):
+			id = tokens[i+2]
+		default:
+			continue
+		}
+
+		if id.Kind == String && id.Text != "" && !strings.Contains(id.Text, "$") {
+			found[id.Text] = true
+		}
+	}
+
+	ids = make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return
+}
@@ -0,0 +1,33 @@
+package jenkinsfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCredentialIDs(t *testing.T) {
+	ids, err := ParseCredentialIDs(`pipeline {
+  environment {
+    TOKEN = credentials('token')
+  }
+  stages {
+    stage('clone') {
+      steps {
+        git(url: 'https://github.com/a/b', credentialsId: 'git-auth')
+        withCredentials([usernamePassword(credentialsId : "docker", passwordVariable: 'P', usernameVariable: 'U')]) {
+          sh 'docker login -u $U -p $P'
+        }
+        withCredentials([string(credentialsId: "${params.id}", variable: 'T')]) {
+          sh 'echo git-auth'
+        }
+      }
+    }
+  }
+}`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"docker", "git-auth", "token"}, ids)
+
+	_, err = ParseCredentialIDs(`credentials('token)`)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,160 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TokenKind is the kind of a token
+type TokenKind int
+
+const (
+	// Ident represents an identifier or a keyword, such as: pipeline, stage, true
+	Ident TokenKind = iota
+	// String represents a quoted string, the Text of it is the content without quotes
+	String
+	// Number represents a numeric literal
+	Number
+	// Punct represents a single punctuation, such as: { } ( ) [ ] , :
+	Punct
+)
+
+// Token is a lexical token of a Jenkinsfile
+type Token struct {
+	Kind TokenKind
+	Text string
+	Line int
+}
+
+// Is checks if the token is a punctuation or an identifier with the given text
+func (t Token) Is(text string) bool {
+	return (t.Kind == Punct || t.Kind == Ident) && t.Text == text
+}
+
+// Tokenize splits a Jenkinsfile into tokens, comments are dropped
+func Tokenize(text string) (tokens []Token, err error) {
+	line := 1
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start := line
+			end := indexOf(runes, i+2, "*/")
+			if end < 0 {
+				err = fmt.Errorf("line %d: unterminated comment", start)
+				return
+			}
+			line += strings.Count(string(runes[i:end]), "\n")
+			i = end + 2
+		case c == '\'' || c == '"':
+			quote := string(c)
+			if i+2 < len(runes) && runes[i+1] == c && runes[i+2] == c {
+				quote = strings.Repeat(quote, 3)
+			}
+
+			start := line
+			var value strings.Builder
+			j := i + len(quote)
+			closed := false
+			for j < len(runes) {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					switch escaped := runes[j+1]; escaped {
+					case 'n':
+						value.WriteRune('\n')
+					case 't':
+						value.WriteRune('\t')
+					case 'r':
+						value.WriteRune('\r')
+					case '\n':
+						line++
+						value.WriteRune(escaped)
+					default:
+						value.WriteRune(escaped)
+					}
+					j += 2
+					continue
+				}
+				if c == '"' && runes[j] == '$' && j+1 < len(runes) && runes[j+1] == '{' {
+					// the expression of a GString might have quotes, such as: "${params.get("name")}"
+					if end := matchBrace(runes, j+1); end > 0 {
+						line += strings.Count(string(runes[j:end]), "\n")
+						value.WriteString(string(runes[j : end+1]))
+						j = end + 1
+						continue
+					}
+				}
+				if strings.HasPrefix(string(runes[j:min(j+len(quote), len(runes))]), quote) {
+					closed = true
+					break
+				}
+				if runes[j] == '\n' {
+					if len(quote) == 1 {
+						break
+					}
+					line++
+				}
+				value.WriteRune(runes[j])
+				j++
+			}
+			if !closed {
+				err = fmt.Errorf("line %d: unterminated string, missing the quote %s", start, quote)
+				return
+			}
+			tokens = append(tokens, Token{Kind: String, Text: value.String(), Line: start})
+			i = j + len(quote)
+		case c == '_' || c == '$' || unicode.IsLetter(c):
+			j := i
+			for j < len(runes) && (runes[j] == '_' || runes[j] == '$' || runes[j] == '.' ||
+				unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, Token{Kind: Ident, Text: string(runes[i:j]), Line: line})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, Token{Kind: Number, Text: string(runes[i:j]), Line: line})
+			i = j
+		default:
+			tokens = append(tokens, Token{Kind: Punct, Text: string(c), Line: line})
+			i++
+		}
+	}
+	return
+}
+
+// matchBrace returns the index of the brace which closes the given one, or -1 if it's not closed
+func matchBrace(runes []rune, from int) int {
+	depth := 0
+	for i := from; i < len(runes); i++ {
+		switch runes[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func indexOf(runes []rune, from int, sub string) int {
+	if index := strings.Index(string(runes[from:]), sub); index >= 0 {
+		return from + len([]rune(string(runes[from:])[:index]))
+	}
+	return -1
+}
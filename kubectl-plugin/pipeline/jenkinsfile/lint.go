@@ -0,0 +1,303 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Problem is an issue of a Jenkinsfile which is found by the linter
+type Problem struct {
+	Line    int
+	Message string
+	// Warning indicates the Jenkinsfile might be valid, the linter is not able to tell it
+	Warning bool
+}
+
+// String returns the text of a problem
+func (p Problem) String() string {
+	if p.Warning {
+		return fmt.Sprintf("line %d: warning: %s", p.Line, p.Message)
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// pipelineDirectives are the directives which are allowed in the pipeline block
+var pipelineDirectives = []string{"agent", "environment", "options", "parameters", "triggers", "tools",
+	"libraries", "stages", "post"}
+
+// stageDirectives are the directives which are allowed in a stage block
+var stageDirectives = []string{"agent", "environment", "input", "options", "post", "tools", "when",
+	"failFast", "steps", "stages", "parallel", "matrix"}
+
+// statement is a statement of a Jenkinsfile, it might have a block body
+type statement struct {
+	name  string
+	label string
+	line  int
+	// start and end are the indexes of the body tokens
+	start    int
+	end      int
+	hasBody  bool
+	children []*statement
+}
+
+// Lint checks the structure of a declarative Jenkinsfile, the scripted Jenkinsfile is not checked.
+// The failures of the lexer are warnings, because it does not understand all the Groovy syntax, such as the slashy strings.
+func Lint(text string) (problems []Problem) {
+	tokens, err := Tokenize(text)
+	if err != nil {
+		problem := toProblem(err)
+		problem.Warning = true
+		problems = append(problems, problem)
+		return
+	}
+	if !hasPipelineBlock(tokens) {
+		return
+	}
+	if problems = checkBrackets(tokens); len(problems) > 0 {
+		return
+	}
+
+	root := parseStatements(tokens, 0, len(tokens))
+	var pipelines []*statement
+	for _, item := range root {
+		if item.name == "pipeline" && item.hasBody {
+			pipelines = append(pipelines, item)
+		}
+	}
+
+	switch {
+	case len(pipelines) > 1:
+		problems = append(problems, Problem{Line: pipelines[1].line, Message: "only one pipeline block is allowed"})
+	case len(pipelines) == 1:
+		problems = lintPipeline(tokens, pipelines[0])
+	}
+	return
+}
+
+// hasPipelineBlock checks if there is a top-level pipeline block, it's the mark of a declarative Jenkinsfile
+func hasPipelineBlock(tokens []Token) bool {
+	depth := 0
+	for i, token := range tokens {
+		switch {
+		case token.Is("{") || token.Is("(") || token.Is("["):
+			depth++
+		case token.Is("}") || token.Is(")") || token.Is("]"):
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0 && token.Kind == Ident && token.Text == "pipeline" &&
+			i+1 < len(tokens) && tokens[i+1].Is("{"):
+			return true
+		}
+	}
+	return false
+}
+
+func lintPipeline(tokens []Token, pipeline *statement) (problems []Problem) {
+	counts := map[string]int{}
+	for _, item := range pipeline.children {
+		counts[item.name]++
+		if !contains(pipelineDirectives, item.name) {
+			problems = append(problems, Problem{Line: item.line,
+				Message: fmt.Sprintf("unknown directive '%s' in the pipeline block", item.name)})
+			continue
+		}
+		if counts[item.name] == 2 {
+			problems = append(problems, Problem{Line: item.line,
+				Message: fmt.Sprintf("duplicate directive '%s' in the pipeline block", item.name)})
+		}
+
+		switch item.name {
+		case "stages":
+			problems = append(problems, lintStages(item, map[string]int{})...)
+		case "parameters":
+			problems = append(problems, lintParameters(tokens, item)...)
+		}
+	}
+
+	if counts["agent"] == 0 {
+		problems = append(problems, Problem{Line: pipeline.line, Message: "the agent directive is missing in the pipeline block"})
+	}
+	if counts["stages"] == 0 {
+		problems = append(problems, Problem{Line: pipeline.line, Message: "the stages block is missing in the pipeline block"})
+	}
+	sortProblems(problems)
+	return
+}
+
+// lintStages checks the stages or parallel block, the names of the stages are recorded for detecting the duplicated ones
+func lintStages(stages *statement, names map[string]int) (problems []Problem) {
+	if !stages.hasBody {
+		problems = append(problems, Problem{Line: stages.line, Message: fmt.Sprintf("the %s directive requires a block", stages.name)})
+		return
+	}
+	if len(stages.children) == 0 {
+		problems = append(problems, Problem{Line: stages.line, Message: fmt.Sprintf("no stages found in the %s block", stages.name)})
+	}
+
+	for _, item := range stages.children {
+		if item.name != "stage" {
+			problems = append(problems, Problem{Line: item.line,
+				Message: fmt.Sprintf("expect a stage in the %s block but got '%s'", stages.name, item.name)})
+			continue
+		}
+		problems = append(problems, lintStage(item, names)...)
+	}
+	return
+}
+
+func lintStage(stage *statement, names map[string]int) (problems []Problem) {
+	if stage.label == "" {
+		problems = append(problems, Problem{Line: stage.line, Message: "the name of stage is missing"})
+	} else if line, ok := names[stage.label]; ok {
+		problems = append(problems, Problem{Line: stage.line,
+			Message: fmt.Sprintf("duplicate stage name '%s', it's declared at line %d", stage.label, line)})
+	} else {
+		names[stage.label] = stage.line
+	}
+
+	if !stage.hasBody {
+		problems = append(problems, Problem{Line: stage.line, Message: fmt.Sprintf("the stage '%s' requires a block", stage.label)})
+		return
+	}
+
+	var executors int
+	for _, item := range stage.children {
+		if !contains(stageDirectives, item.name) {
+			problems = append(problems, Problem{Line: item.line,
+				Message: fmt.Sprintf("unknown directive '%s' in the stage '%s'", item.name, stage.label)})
+			continue
+		}
+
+		switch item.name {
+		case "steps":
+			executors++
+			if !item.hasBody || len(item.children) == 0 {
+				problems = append(problems, Problem{Line: item.line,
+					Message: fmt.Sprintf("no steps found in the stage '%s'", stage.label)})
+			}
+		case "stages", "parallel":
+			executors++
+			problems = append(problems, lintStages(item, names)...)
+		case "matrix":
+			executors++
+		}
+	}
+
+	if executors != 1 {
+		problems = append(problems, Problem{Line: stage.line,
+			Message: fmt.Sprintf("the stage '%s' must have only one of steps, stages, parallel or matrix", stage.label)})
+	}
+	return
+}
+
+func lintParameters(tokens []Token, parameters *statement) (problems []Problem) {
+	if !parameters.hasBody {
+		problems = append(problems, Problem{Line: parameters.line, Message: "the parameters directive requires a block"})
+		return
+	}
+
+	params, err := parseParameterBlock(tokens[parameters.start:parameters.end])
+	if err != nil {
+		problems = append(problems, toProblem(err))
+		return
+	}
+
+	names := map[string]bool{}
+	for _, param := range params {
+		if names[param.Name] {
+			problems = append(problems, Problem{Line: parameters.line,
+				Message: fmt.Sprintf("duplicate parameter name '%s'", param.Name)})
+		}
+		names[param.Name] = true
+	}
+	return
+}
+
+// checkBrackets makes sure all the brackets are balanced
+func checkBrackets(tokens []Token) (problems []Problem) {
+	pairs := map[string]string{"{": "}", "(": ")", "[": "]"}
+	var stack []Token
+	for _, token := range tokens {
+		if token.Kind != Punct {
+			continue
+		}
+
+		if _, ok := pairs[token.Text]; ok {
+			stack = append(stack, token)
+		} else if token.Text == "}" || token.Text == ")" || token.Text == "]" {
+			if len(stack) == 0 || pairs[stack[len(stack)-1].Text] != token.Text {
+				problems = append(problems, Problem{Line: token.Line, Message: fmt.Sprintf("unexpected '%s'", token.Text)})
+				return
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	for _, token := range stack {
+		problems = append(problems, Problem{Line: token.Line, Message: fmt.Sprintf("'%s' is not closed", token.Text)})
+	}
+	return
+}
+
+// parseStatements parses the statements between the given indexes, the brackets must be balanced
+func parseStatements(tokens []Token, start, end int) (statements []*statement) {
+	for i := start; i < end; {
+		item := &statement{line: tokens[i].Line}
+		if tokens[i].Kind == Ident {
+			item.name = tokens[i].Text
+		}
+
+		for first := i; i < end; i++ {
+			token := tokens[i]
+			if token.Is("{") {
+				closing, _ := matchBracket(tokens, i)
+				item.hasBody, item.start, item.end = true, i+1, closing
+				item.children = parseStatements(tokens, i+1, closing)
+				i = closing + 1
+				break
+			}
+
+			// a statement ends at the end of line unless it's continued by a comma or an operator
+			if i > first {
+				last := tokens[i-1]
+				if token.Line != last.Line && !(last.Kind == Punct && last.Text != ")" && last.Text != "]") {
+					break
+				}
+			}
+
+			if token.Is("(") || token.Is("[") {
+				closing, _ := matchBracket(tokens, i)
+				if token.Is("(") && item.label == "" && i+1 < closing && tokens[i+1].Kind == String {
+					item.label = tokens[i+1].Text
+				}
+				i = closing
+			}
+		}
+		statements = append(statements, item)
+	}
+	return
+}
+
+func toProblem(err error) (problem Problem) {
+	problem.Message = err.Error()
+	if _, scanErr := fmt.Sscanf(problem.Message, "line %d: ", &problem.Line); scanErr == nil {
+		problem.Message = problem.Message[len(fmt.Sprintf("line %d: ", problem.Line)):]
+	}
+	return
+}
+
+func sortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+}
+
+func contains(items []string, item string) bool {
+	for _, val := range items {
+		if val == item {
+			return true
+		}
+	}
+	return false
+}
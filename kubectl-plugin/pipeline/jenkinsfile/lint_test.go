@@ -0,0 +1,168 @@
+package jenkinsfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		problems []string
+	}{{
+		name: "valid",
+		text: `@Library('shared') _
+pipeline {
+  agent {
+    node { label 'base' }
+  }
+  parameters {
+    string(name: 'name', defaultValue: 'ks')
+    booleanParam name: 'debug',
+      defaultValue: false
+  }
+  options {
+    timeout(time: 1, unit: 'HOURS')
+  }
+  stages {
+    stage('build')
+    {
+      when { branch 'master' }
+      steps {
+        sh '''make build'''
+        script {
+          if (params.debug) {
+            echo "debug"
+          } else {
+            echo "}"
+          }
+        }
+      }
+    }
+    stage('test') {
+      parallel {
+        stage('unit') { steps { sh 'make test' } }
+        stage('e2e') {
+          steps {
+            withCredentials([usernamePassword(credentialsId: 'id', usernameVariable: 'u', passwordVariable: 'p')]) {
+              sh 'make e2e'
+            }
+          }
+        }
+      }
+    }
+  }
+  post {
+    always { echo 'done' }
+  }
+}`,
+	}, {
+		name: "scripted",
+		text: `node { stage('build') { sh 'make' } }`,
+	}, {
+		name: "scripted with podTemplate",
+		text: `podTemplate(label: 'go') {
+  node('go') { stage('build') { sh 'make' } }
+}`,
+	}, {
+		name: "scripted with timestamps",
+		text: `timestamps { echo 'hello' }`,
+	}, {
+		name: "scripted with a function",
+		text: `def build(name) { echo name }
+build('ks')`,
+	}, {
+		name: "scripted with slashy string",
+		text: `def pattern = /v{1}\d+/
+if (env.TAG_NAME ==~ pattern) { echo 'release' }`,
+	}, {
+		name: "GString with nested quotes",
+		text: `pipeline {
+  agent any
+  stages { stage('a') { steps { echo "${params.get("name")} and ${env.getProperty('HOME')}" } } }
+}`,
+	}, {
+		name:     "unterminated string",
+		text:     "pipeline {\n  agent any\n  stages { stage('build) { steps { echo 'a' } } }\n}",
+		problems: []string{"line 3: warning: unterminated string, missing the quote '"},
+	}, {
+		name:     "slashy string with a quote",
+		text:     "pipeline {\n  agent any\n  stages { stage('a') { steps { script { if ('a' ==~ /\"/) { echo 'a' } } } } }\n}",
+		problems: []string{"line 3: warning: unterminated string, missing the quote \""},
+	}, {
+		name:     "unbalanced braces",
+		text:     "pipeline {\n  agent any\n  stages {\n    stage('build') { steps { echo 'a' } }\n}",
+		problems: []string{"line 1: '{' is not closed"},
+	}, {
+		name:     "unexpected brace",
+		text:     "pipeline {\n  agent any\n}\n}",
+		problems: []string{"line 4: unexpected '}'"},
+	}, {
+		name: "no pipeline block",
+		text: "echo 'hello'",
+	}, {
+		name:     "multiple pipeline blocks",
+		text:     "pipeline {\n  agent any\n  stages { stage('a') { steps { echo 'a' } } }\n}\npipeline { }",
+		problems: []string{"line 5: only one pipeline block is allowed"},
+	}, {
+		name: "missing agent and stages",
+		text: "pipeline {\n  environment { A = 'b' }\n  foo { }\n}",
+		problems: []string{"line 1: the agent directive is missing in the pipeline block",
+			"line 1: the stages block is missing in the pipeline block",
+			"line 3: unknown directive 'foo' in the pipeline block"},
+	}, {
+		name: "invalid stages",
+		text: `pipeline {
+  agent any
+  stages {
+    stage('build') { steps { echo 'a' } }
+    stage('build') { steps { echo 'b' } }
+    steps { echo 'c' }
+    stage('test') {
+      steps { }
+      stages { stage('unit') { steps { echo 'd' } } }
+      script { }
+    }
+    stage { steps { echo 'e' } }
+  }
+}`,
+		problems: []string{"line 5: duplicate stage name 'build', it's declared at line 4",
+			"line 6: expect a stage in the stages block but got 'steps'",
+			"line 7: the stage 'test' must have only one of steps, stages, parallel or matrix",
+			"line 8: no steps found in the stage 'test'",
+			"line 10: unknown directive 'script' in the stage 'test'",
+			"line 12: the name of stage is missing"},
+	}, {
+		name: "invalid parameters",
+		text: `pipeline {
+  agent any
+  parameters {
+    string(name: 'a')
+    text(name: 'a')
+  }
+  stages { stage('a') { steps { echo 'a' } } }
+}`,
+		problems: []string{"line 3: duplicate parameter name 'a'"},
+	}, {
+		name: "parameter without name",
+		text: `pipeline {
+  agent any
+  parameters {
+    string(defaultValue: 'a')
+  }
+  stages { stage('a') { steps { echo 'a' } } }
+}`,
+		problems: []string{"line 4: the name of parameter 'string' is missing"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var problems []string
+			for _, problem := range Lint(tt.text) {
+				problems = append(problems, problem.String())
+			}
+			assert.Equal(t, tt.problems, problems)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parameter types of a Pipeline
+const (
+	ParameterTypeString   = "string"
+	ParameterTypeText     = "text"
+	ParameterTypeBoolean  = "boolean"
+	ParameterTypeChoice   = "choice"
+	ParameterTypePassword = "password"
+	ParameterTypeFile     = "file"
+)
+
+// Parameter is a declared parameter of a Pipeline
+type Parameter struct {
+	Name         string
+	Type         string
+	DefaultValue string
+	Description  string
+	Choices      []string
+}
+
+// parameterTypes maps the parameter steps of Jenkinsfile to the parameter types
+var parameterTypes = map[string]string{
+	"string":       ParameterTypeString,
+	"text":         ParameterTypeText,
+	"booleanParam": ParameterTypeBoolean,
+	"choice":       ParameterTypeChoice,
+	"password":     ParameterTypePassword,
+	"file":         ParameterTypeFile,
+	"base64File":   ParameterTypeFile,
+	"stashedFile":  ParameterTypeFile,
+}
+
+// ParseParameters returns the parameters which are declared in the parameters directive of a declarative Jenkinsfile,
+// it's nil if there is no parameters directive
+func ParseParameters(text string) (params []Parameter, err error) {
+	var tokens []Token
+	if tokens, err = Tokenize(text); err != nil {
+		return
+	}
+
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].Kind != Ident || tokens[i].Text != "parameters" || !tokens[i+1].Is("{") {
+			continue
+		}
+		if params == nil {
+			params = []Parameter{}
+		}
+
+		var end int
+		if end, err = matchBracket(tokens, i+1); err != nil {
+			return
+		}
+
+		var blockParams []Parameter
+		if blockParams, err = parseParameterBlock(tokens[i+2 : end]); err != nil {
+			return
+		}
+		params = append(params, blockParams...)
+		i = end
+	}
+	return
+}
+
+func parseParameterBlock(tokens []Token) (params []Parameter, err error) {
+	for i := 0; i < len(tokens); {
+		step := tokens[i]
+		if step.Kind != Ident {
+			err = fmt.Errorf("line %d: unexpected '%s' in parameters", step.Line, step.Text)
+			return
+		}
+
+		var args map[string]interface{}
+		if args, i, err = parseStepArgs(tokens, i+1, step.Line); err != nil {
+			return
+		}
+
+		param := Parameter{
+			Type:        parameterTypes[step.Text],
+			Name:        toString(args["name"]),
+			Description: toString(args["description"]),
+		}
+		if param.Type == "" {
+			param.Type = step.Text
+		}
+		if param.Name == "" {
+			err = fmt.Errorf("line %d: the name of parameter '%s' is missing", step.Line, step.Text)
+			return
+		}
+
+		switch choices := args["choices"].(type) {
+		case []string:
+			param.Choices = choices
+		case string:
+			param.Choices = strings.Split(choices, "\n")
+		}
+		if param.Type == ParameterTypeChoice && len(param.Choices) > 0 {
+			param.DefaultValue = param.Choices[0]
+		} else {
+			param.DefaultValue = toString(args["defaultValue"])
+		}
+		params = append(params, param)
+	}
+	return
+}
+
+// parseStepArgs parses the named arguments of a step which starts from the given index,
+// the arguments could be surrounded with parentheses or not
+func parseStepArgs(tokens []Token, start, line int) (args map[string]interface{}, next int, err error) {
+	args = map[string]interface{}{}
+	end := len(tokens)
+	i := start
+	if i < len(tokens) && tokens[i].Is("(") {
+		if end, err = matchBracket(tokens, i); err != nil {
+			return
+		}
+		i++
+	}
+
+	for i < end {
+		// a step without parentheses ends at the end of line unless it's followed by a comma
+		if end == len(tokens) && tokens[i].Line != line {
+			break
+		}
+
+		if i+2 >= len(tokens) || tokens[i].Kind != Ident || !tokens[i+1].Is(":") {
+			err = fmt.Errorf("line %d: expect a named argument but got '%s'", tokens[i].Line, tokens[i].Text)
+			return
+		}
+		key := tokens[i].Text
+
+		var value interface{}
+		if value, i, err = parseValue(tokens, i+2); err != nil {
+			return
+		}
+		args[key] = value
+		line = tokens[i-1].Line
+
+		if i < end && tokens[i].Is(",") {
+			if i+1 < len(tokens) {
+				line = tokens[i+1].Line
+			}
+			i++
+		}
+	}
+
+	next = i
+	if end < len(tokens) {
+		next = end + 1
+	}
+	return
+}
+
+func parseValue(tokens []Token, i int) (value interface{}, next int, err error) {
+	token := tokens[i]
+	if !token.Is("[") {
+		value, next = token.Text, i+1
+		return
+	}
+
+	var end int
+	if end, err = matchBracket(tokens, i); err != nil {
+		return
+	}
+	items := []string{}
+	for _, item := range tokens[i+1 : end] {
+		if !item.Is(",") {
+			items = append(items, item.Text)
+		}
+	}
+	value, next = items, end+1
+	return
+}
+
+// matchBracket returns the index of the bracket which matches the one at the given index
+func matchBracket(tokens []Token, start int) (end int, err error) {
+	pairs := map[string]string{"{": "}", "(": ")", "[": "]"}
+	var stack []string
+	for end = start; end < len(tokens); end++ {
+		token := tokens[end]
+		if token.Kind != Punct {
+			continue
+		}
+
+		if closing, ok := pairs[token.Text]; ok {
+			stack = append(stack, closing)
+		} else if token.Text == "}" || token.Text == ")" || token.Text == "]" {
+			if len(stack) == 0 || stack[len(stack)-1] != token.Text {
+				err = fmt.Errorf("line %d: unexpected '%s'", token.Line, token.Text)
+				return
+			}
+			if stack = stack[:len(stack)-1]; len(stack) == 0 {
+				return
+			}
+		}
+	}
+	err = fmt.Errorf("line %d: '%s' is not closed", tokens[start].Line, tokens[start].Text)
+	return
+}
+
+func toString(value interface{}) (result string) {
+	result, _ = value.(string)
+	return
+}
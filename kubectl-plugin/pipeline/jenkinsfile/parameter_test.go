@@ -0,0 +1,86 @@
+package jenkinsfile
+
+import (
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/tpl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParameters(t *testing.T) {
+	tests := []struct {
+		name        string
+		jenkinsfile string
+		wantParams  []Parameter
+		wantErr     bool
+	}{{
+		name:        "without parameters",
+		jenkinsfile: tpl.GetSimple(),
+	}, {
+		name:        "empty parameters",
+		jenkinsfile: `pipeline { parameters { } }`,
+		wantParams:  []Parameter{},
+	}, {
+		name: "parameters of a scripted Pipeline are unknown",
+		jenkinsfile: `properties([parameters([string(name: 'a', defaultValue: 'b')])])
+node { echo params.a }`,
+	}, {
+		name:        "steps without parentheses",
+		jenkinsfile: tpl.GetParameter(),
+		wantParams: []Parameter{{
+			Name: "name", Type: ParameterTypeString, DefaultValue: "rick", Description: "just for testing",
+		}, {
+			Name: "debug", Type: ParameterTypeBoolean, DefaultValue: "false",
+			Description: "You can use this flag to debug your Pipeline",
+		}, {
+			Name: "kubernetesVersion", Type: ParameterTypeChoice, DefaultValue: "v1.18.8",
+			Description: "Please choose the target Kubernetes version", Choices: []string{"v1.18.8", "v1.18.9"},
+		}},
+	}, {
+		name: "steps with parentheses",
+		jenkinsfile: `pipeline {
+  agent any
+  parameters {
+    // the comment should be ignored
+    string(name: "PERSON", defaultValue: 'Mr Jenkins',
+      description: 'Who should I say hello to?')
+    choice(name: 'CHOICE', choices: 'One\nTwo', description: '')
+    password(name: 'PASSWORD', defaultValue: 'SECRET')
+  }
+}`,
+		wantParams: []Parameter{{
+			Name: "PERSON", Type: ParameterTypeString, DefaultValue: "Mr Jenkins", Description: "Who should I say hello to?",
+		}, {
+			Name: "CHOICE", Type: ParameterTypeChoice, DefaultValue: "One", Choices: []string{"One", "Two"},
+		}, {
+			Name: "PASSWORD", Type: ParameterTypePassword, DefaultValue: "SECRET",
+		}},
+	}, {
+		name:        "parameter without name",
+		jenkinsfile: `pipeline { parameters { string(defaultValue: 'a') } }`,
+		wantErr:     true,
+	}, {
+		name:        "unclosed parameters",
+		jenkinsfile: `pipeline { parameters { string(name: 'a')`,
+		wantErr:     true,
+	}, {
+		name:        "expression is not supported",
+		jenkinsfile: `pipeline { parameters { string(name: 'a', defaultValue: 'a' + 'b') } }`,
+		wantErr:     true,
+	}, {
+		name:        "unterminated string",
+		jenkinsfile: `pipeline { parameters { string(name: 'a) } }`,
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := ParseParameters(tt.jenkinsfile)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantParams, params)
+		})
+	}
+}
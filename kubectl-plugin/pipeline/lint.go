@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/jenkinsfile"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newPipelineLintCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineLintOption{client: client}
+	cmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check the Jenkinsfile without Jenkins",
+		Long: `Check the Jenkinsfile without Jenkins
+It checks the structure of a declarative Jenkinsfile, such as: the pipeline, agent, stages, stage and steps nesting,
+the unbalanced braces and quotes, the duplicate stage names, the unknown directives, and the parameters.
+A scripted Jenkinsfile, which has no top-level pipeline block, is not checked. The Groovy syntax which is not
+understood, such as some slashy strings, is reported as a warning.
+The target could be a local file, a Pipeline in the form of namespace/name, or '-' which means the standard input.
+It checks the file Jenkinsfile in the current directory if there is no target given.`,
+		Example: `ks pip lint
+ks pip lint ci/Jenkinsfile
+ks pip lint devops-ns/my-pipeline`,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+	return
+}
+
+type pipelineLintOption struct {
+	target string
+
+	// inner fields
+	client dynamic.Interface
+}
+
+func (o *pipelineLintOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.target = "Jenkinsfile"; len(args) > 0 {
+		o.target = args[0]
+	}
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	return
+}
+
+func (o *pipelineLintOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	var text string
+	if text, err = o.getJenkinsfile(cmd); err != nil {
+		return
+	}
+
+	count := 0
+	for _, problem := range jenkinsfile.Lint(text) {
+		if problem.Warning {
+			cmd.Printf("%s:%d: warning: %s\n", o.target, problem.Line, problem.Message)
+			continue
+		}
+		cmd.Printf("%s:%d: %s\n", o.target, problem.Line, problem.Message)
+		count++
+	}
+	if count > 0 {
+		err = fmt.Errorf("found %d problems in %s", count, o.target)
+	} else {
+		cmd.Printf("no problems found in %s\n", o.target)
+	}
+	return
+}
+
+func (o *pipelineLintOption) getJenkinsfile(cmd *cobra.Command) (text string, err error) {
+	var data []byte
+	if o.target == "-" {
+		data, err = ioutil.ReadAll(cmd.InOrStdin())
+		text = string(data)
+		return
+	}
+
+	if _, statErr := os.Stat(o.target); statErr == nil || !strings.Contains(o.target, "/") ||
+		strings.Count(o.target, "/") > 1 {
+		if data, err = ioutil.ReadFile(o.target); err != nil {
+			err = fmt.Errorf("failed to read %s, error: %v", o.target, err)
+		}
+		text = string(data)
+		return
+	}
+
+	ns, name := strings.Split(o.target, "/")[0], strings.Split(o.target, "/")[1]
+	var pip *unstructured.Unstructured
+	if pip, err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+		err = fmt.Errorf("failed to get Pipeline %s, error: %v", o.target, err)
+		return
+	}
+
+	var found bool
+	if text, found, _ = unstructured.NestedString(pip.Object, "spec", "pipeline", "jenkinsfile"); !found {
+		err = fmt.Errorf("no Jenkinsfile found in Pipeline %s", o.target)
+	}
+	return
+}
+
+// lintJenkinsfile returns an error which contains all the problems of the Jenkinsfile, the warnings are printed only
+func lintJenkinsfile(cmd *cobra.Command, text string) (err error) {
+	var messages []string
+	for _, problem := range jenkinsfile.Lint(text) {
+		if problem.Warning {
+			cmd.PrintErrf("%s\n", problem.String())
+			continue
+		}
+		messages = append(messages, problem.String())
+	}
+	if len(messages) > 0 {
+		err = fmt.Errorf("found %d problems in the Jenkinsfile, use --skip-lint to ignore them:\n%s",
+			len(messages), strings.Join(messages, "\n"))
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/tpl"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintBuiltinTemplates(t *testing.T) {
+	for _, text := range []string{tpl.GetBuildJava(), tpl.GetBuildGo(), tpl.GetSimple(), tpl.GetParameter(),
+		tpl.GetLongRunPipeline(), tpl.GetParallel()} {
+		assert.Nil(t, lintJenkinsfile(&cobra.Command{}, text))
+	}
+}
+
+func TestLintJenkinsfile(t *testing.T) {
+	cmd := &cobra.Command{}
+	buf := bytes.NewBuffer(nil)
+	cmd.SetErr(buf)
+
+	// the scripted Jenkinsfile is not checked
+	assert.Nil(t, lintJenkinsfile(cmd, "podTemplate(label: 'go') {\n  node('go') { sh 'make' }\n}"))
+	// the failure of the lexer is a warning
+	assert.Nil(t, lintJenkinsfile(cmd, "def pattern = /\"/"))
+	assert.Equal(t, "line 1: warning: unterminated string, missing the quote \"\n", buf.String())
+
+	err := lintJenkinsfile(cmd, "pipeline {\n  agent any\n}")
+	assert.EqualError(t, err, "found 1 problems in the Jenkinsfile, use --skip-lint to ignore them:\n"+
+		"line 1: the stages block is missing in the pipeline block")
+}
+
+func TestPipelineLintCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	file := filepath.Join(dir, "Jenkinsfile")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("pipeline {\n  stages { }\n}"), 0644))
+	scripted := filepath.Join(dir, "scripted")
+	assert.Nil(t, ioutil.WriteFile(scripted, []byte("timestamps {\n  echo 'it\\'s ${\"a\"}'\n  echo \"it's\n}"), 0644))
+
+	client := newFakeDynamicClient(newFakePipeline("ns", "good", tpl.GetSimple()))
+
+	tests := []struct {
+		name    string
+		args    []string
+		output  string
+		wantErr bool
+	}{{
+		name:    "local file",
+		args:    []string{file},
+		output:  file + ":1: the agent directive is missing in the pipeline block\n" + file + ":2: no stages found in the stages block\n",
+		wantErr: true,
+	}, {
+		name:   "scripted with a warning",
+		args:   []string{scripted},
+		output: scripted + ":3: warning: unterminated string, missing the quote \"\nno problems found in " + scripted + "\n",
+	}, {
+		name:   "pipeline in cluster",
+		args:   []string{"ns/good"},
+		output: "no problems found in ns/good\n",
+	}, {
+		name:    "pipeline not found",
+		args:    []string{"ns/fake"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newPipelineLintCmd(client)
+			buf := bytes.NewBuffer(nil)
+			cmd.SetOut(buf)
+			cmd.SetArgs(tt.args)
+			cmd.SilenceUsage, cmd.SilenceErrors = true, true
+			err := cmd.Execute()
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if tt.output != "" {
+				assert.Equal(t, tt.output, buf.String())
+			}
+		})
+	}
+}
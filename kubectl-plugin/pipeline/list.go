@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+)
+
+// pipelineListOption lists the Pipelines of the root command
+type pipelineListOption struct {
+	output        string
+	allNamespaces bool
+
+	// inner fields
+	client dynamic.Interface
+}
+
+func (o *pipelineListOption) addFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVarP(&o.output, "output", "o", "",
+		"The output format, supported formats: yaml, json, name, jsonpath=<template>, wide")
+	flags.BoolVarP(&o.allNamespaces, "all-namespaces", "A", false,
+		"List the Pipelines of all the DevOps projects")
+	_ = cmd.RegisterFlagCompletionFunc("output", common.ArrayCompletion(outputFormatYAML, outputFormatJSON,
+		outputFormatName, outputFormatJSONPath, outputFormatWide))
+}
+
+func (o *pipelineListOption) runE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	if o.output != "" && o.output != outputFormatWide && !isObjectOutputFormat(o.output) {
+		err = fmt.Errorf("not supported output format: %s", o.output)
+		return
+	}
+
+	var namespaces []string
+	if o.allNamespaces {
+		namespaces = getAllNamespace(o.client)
+	} else {
+		var ns string
+		if ns, err = getNamespace(o.client, args); err != nil {
+			return
+		}
+		namespaces = []string{ns}
+	}
+
+	ctx := context.TODO()
+	var items []unstructured.Unstructured
+	for _, ns := range namespaces {
+		var list *unstructured.UnstructuredList
+		if list, err = o.client.Resource(types.GetPipelineSchema()).Namespace(ns).List(ctx, metav1.ListOptions{}); err != nil {
+			err = fmt.Errorf("failed to get Pipeline list in namespace '%s', error: %v", ns, err)
+			return
+		}
+		items = append(items, list.Items...)
+	}
+
+	if isObjectOutputFormat(o.output) {
+		err = printObjects(cmd.OutOrStdout(), o.output, items)
+		return
+	}
+
+	var rows [][]string
+	if rows, err = o.getRows(ctx, items); err == nil {
+		err = common.PrintTable(cmd.OutOrStdout(), o.getHeaders(), rows)
+	}
+	return
+}
+
+func (o *pipelineListOption) getHeaders() (headers []string) {
+	if o.allNamespaces {
+		headers = append(headers, "NAMESPACE")
+	}
+	headers = append(headers, "NAME", "TYPE", "AGE")
+	if o.output == outputFormatWide {
+		headers = append(headers, "SCM", "DISCARDER", "LAST RUN PHASE", "LAST RUN")
+	}
+	return
+}
+
+func (o *pipelineListOption) getRows(ctx context.Context, items []unstructured.Unstructured) (rows [][]string, err error) {
+	now := time.Now()
+	for i := range items {
+		item := &items[i]
+		var row []string
+		if o.allNamespaces {
+			row = append(row, item.GetNamespace())
+		}
+		pType, _, _ := unstructured.NestedString(item.Object, "spec", "type")
+		row = append(row, item.GetName(), common.EmptyAsNone(pType),
+			duration.HumanDuration(now.Sub(item.GetCreationTimestamp().Time)))
+
+		if o.output == outputFormatWide {
+			var runs []unstructured.Unstructured
+			if runs, err = getPipelineRunList(ctx, o.client, item.GetNamespace(), item.GetName()); err != nil {
+				return
+			}
+			lastRunPhase, lastRun := "<none>", "<none>"
+			if len(runs) > 0 {
+				lastRunPhase = common.EmptyAsNone(getPipelineRunPhase(&runs[0]))
+				lastRun = duration.HumanDuration(now.Sub(runs[0].GetCreationTimestamp().Time))
+			}
+			row = append(row, common.EmptyAsNone(getPipelineSCM(item)), common.EmptyAsNone(getPipelineDiscarder(item)),
+				lastRunPhase, lastRun)
+		}
+		rows = append(rows, row)
+	}
+	return
+}
+
+// getPipelineSCM returns the SCM source of a multi-branch Pipeline, such as: github:owner/repo
+func getPipelineSCM(pipeline *unstructured.Unstructured) (scm string) {
+	sourceType, _, _ := unstructured.NestedString(pipeline.Object, "spec", "multi_branch_pipeline", "source_type")
+	if sourceType == "" {
+		return
+	}
+
+	source, _, _ := unstructured.NestedMap(pipeline.Object, "spec", "multi_branch_pipeline", sourceType+"_source")
+	var repo string
+	for _, key := range []string{"url", "remote"} {
+		if val, ok := source[key].(string); ok && val != "" {
+			repo = val
+		}
+	}
+	if repo == "" {
+		owner, _ := source["owner"].(string)
+		repo, _ = source["repo"].(string)
+		if owner != "" && !strings.Contains(repo, "/") {
+			repo = owner + "/" + repo
+		}
+	}
+
+	scm = sourceType
+	if repo != "" {
+		scm = fmt.Sprintf("%s:%s", sourceType, repo)
+	}
+	return
+}
+
+// getPipelineDiscarder returns the days_to_keep and num_to_keep of a Pipeline
+func getPipelineDiscarder(pipeline *unstructured.Unstructured) (discarder string) {
+	pType, _, _ := unstructured.NestedString(pipeline.Object, "spec", "type")
+	contentKey := pType
+	if pType == option.MultiBranchPipelineType {
+		contentKey = "multi_branch_pipeline"
+	}
+
+	days, daysOK, _ := unstructured.NestedString(pipeline.Object, "spec", contentKey, "discarder", "days_to_keep")
+	num, numOK, _ := unstructured.NestedString(pipeline.Object, "spec", contentKey, "discarder", "num_to_keep")
+	if daysOK || numOK {
+		discarder = fmt.Sprintf("days=%s,num=%s", common.EmptyAsNone(days), common.EmptyAsNone(num))
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPipelineList(t *testing.T) {
+	now := time.Now()
+	multiBranch := newFakePipeline("other", "multi", "")
+	multiBranch.Object["spec"] = map[string]interface{}{
+		"type": option.MultiBranchPipelineType,
+		"multi_branch_pipeline": map[string]interface{}{
+			"discarder":     map[string]interface{}{"days_to_keep": "-1", "num_to_keep": "5"},
+			"source_type":   "github",
+			"github_source": map[string]interface{}{"owner": "devops-ws", "repo": "learn-pipeline-java"},
+		},
+	}
+	run := newFakePipelineRun("run-1", "pip", now.Add(-time.Hour))
+	_ = unstructured.SetNestedField(run.Object, option.PipelinerunPhaseSucceeded, "status", "phase")
+	client := newFakeDynamicClient(newFakeGCPipeline("ns", "pip", "7", "10"), multiBranch, run,
+		newFakeNamespace("ns"), newFakeNamespace("other"))
+
+	buf := bytes.NewBuffer(nil)
+	cmd := NewPipelineCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "-o", "wide"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "LAST RUN PHASE")
+	assert.Contains(t, buf.String(), "days=7,num=10")
+	assert.Contains(t, buf.String(), option.PipelinerunPhaseSucceeded)
+	assert.NotContains(t, buf.String(), "multi")
+
+	buf.Reset()
+	cmd = NewPipelineCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--all-namespaces", "-o", "wide"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "NAMESPACE")
+	assert.Contains(t, buf.String(), "github:devops-ws/learn-pipeline-java")
+	assert.Contains(t, buf.String(), "days=-1,num=5")
+
+	buf.Reset()
+	cmd = NewPipelineCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-A", "-o", "jsonpath={.items[*].metadata.name}"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "pip multi", buf.String())
+
+	cmd = NewPipelineCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"ns", "-o", "fake"})
+	assert.NotNil(t, cmd.Execute())
+}
+
+func newFakeNamespace(name string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	ns.SetLabels(map[string]string{"kubesphere.io/devopsproject": name})
+	return ns
+}
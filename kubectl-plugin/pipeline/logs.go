@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newPipelineLogsCmd() (cmd *cobra.Command) {
+	opt := &pipelineLogsOption{}
+	cmd = &cobra.Command{
+		Use:     "logs",
+		Aliases: []string{"log"},
+		Short:   "Output the log of a PipelineRun",
+		Long: `Output the log of a PipelineRun
+The latest PipelineRun of the Pipeline will be used if there is no PipelineRun name given.`,
+		Example: `ks pip logs -n devops-ns my-pipeline
+ks pip logs -n devops-ns my-pipeline my-pipeline-abcde --follow`,
+		Args:    cobra.MaximumNArgs(2),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.namespace, "namespace", "n", "",
+		"The namespace of target Pipeline")
+	flags.BoolVarP(&opt.follow, "follow", "f", false,
+		"Specify if the log should be streamed until the PipelineRun completes")
+	flags.DurationVarP(&opt.interval, "interval", "", 2*time.Second,
+		"The interval to poll the log when following it")
+	opt.addDevOpsAPIFlags(flags)
+	return
+}
+
+type pipelineLogsOption struct {
+	namespace string
+	pipeline  string
+	run       string
+	follow    bool
+	interval  time.Duration
+	devopsAPIOption
+
+	// inner fields
+	client dynamic.Interface
+}
+
+func (o *pipelineLogsOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	o.client = common.GetDynamicClient(cmd.Root().Context())
+
+	if len(args) > 0 {
+		o.pipeline = args[0]
+	}
+	if len(args) > 1 {
+		o.run = args[1]
+	}
+
+	if o.namespace == "" {
+		if o.namespace, err = getNamespace(o.client, nil); err != nil {
+			return
+		}
+	}
+
+	if o.pipeline == "" && o.run == "" {
+		var pips []string
+		if _, pips, err = getPipelines(o.client, []string{o.namespace}); err != nil {
+			return
+		}
+		if len(pips) == 0 {
+			err = fmt.Errorf("no Pipelines found in namespace '%s'", o.namespace)
+			return
+		}
+		if o.pipeline, err = option.ChooseObjectFromArray("pipeline name", pips); err != nil {
+			return
+		}
+	}
+	err = o.initDevopsClient()
+	return
+}
+
+func (o *pipelineLogsOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	var pipelineRun *unstructured.Unstructured
+	if o.run != "" {
+		pipelineRun, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(o.namespace).Get(ctx, o.run, metav1.GetOptions{})
+	} else {
+		pipelineRun, err = getLatestPipelineRun(ctx, o.client, o.namespace, o.pipeline)
+	}
+	if err != nil {
+		return
+	}
+
+	var start int64
+	pipeline := getPipelineRunPipeline(pipelineRun)
+	for {
+		completed := isCompletedPhase(getPipelineRunPhase(pipelineRun))
+		if runID := pipelineRun.GetAnnotations()[option.PipelinerunIdAnnotationKey]; runID != "" {
+			var chunk *runLogChunk
+			if chunk, err = o.writeRunLog(ctx, cmd.OutOrStdout(), o.namespace, pipeline,
+				getPipelineRunBranch(pipelineRun), runID, start); err != nil {
+				err = fmt.Errorf("failed to get the log of PipelineRun %s/%s, error: %v", o.namespace, pipelineRun.GetName(), err)
+				return
+			}
+			start = chunk.next
+
+			if !o.follow || (completed && !chunk.more) {
+				return
+			}
+		} else if !o.follow || completed {
+			err = fmt.Errorf("PipelineRun %s/%s has no Jenkins run ID, it might be not started yet",
+				o.namespace, pipelineRun.GetName())
+			return
+		}
+
+		time.Sleep(o.interval)
+		if pipelineRun, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(o.namespace).Get(ctx,
+			pipelineRun.GetName(), metav1.GetOptions{}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package pipeline
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// unifiedDiff returns the unified diff of two texts, it's empty if there is no difference
+func unifiedDiff(name, from, to string) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitDiffLines(from),
+		B:        splitDiffLines(to),
+		FromFile: name,
+		ToFile:   name,
+		Context:  3,
+	})
+	return diff
+}
+
+// mergeHunk is a change of the base lines which are in the range [start, end)
+type mergeHunk struct {
+	start int
+	end   int
+	lines []string
+	ours  bool
+}
+
+// mergeText merges the changes of ours and theirs which are based on the same text.
+// The conflicts are surrounded with the markers like git does.
+func mergeText(base, ours, theirs string) (result string, conflict bool) {
+	baseLines := splitLines(base)
+	hunks := append(getMergeHunks(baseLines, splitLines(ours), true),
+		getMergeHunks(baseLines, splitLines(theirs), false)...)
+	sort.SliceStable(hunks, func(i, j int) bool {
+		return hunks[i].start < hunks[j].start
+	})
+
+	var buf strings.Builder
+	pos := 0
+	for i := 0; i < len(hunks); {
+		// group the overlapped hunks
+		start, end := hunks[i].start, hunks[i].end
+		j := i + 1
+		for ; j < len(hunks); j++ {
+			if hunks[j].start > end || (hunks[j].start == end && hunks[j].start != start) {
+				break
+			}
+			if hunks[j].end > end {
+				end = hunks[j].end
+			}
+		}
+		group := hunks[i:j]
+		i = j
+
+		writeLines(&buf, baseLines[pos:start])
+		pos = end
+
+		oursText := applyHunks(baseLines, start, end, group, true)
+		theirsText := applyHunks(baseLines, start, end, group, false)
+		baseText := strings.Join(baseLines[start:end], "")
+		switch {
+		case oursText == theirsText || theirsText == baseText:
+			buf.WriteString(oursText)
+		case oursText == baseText:
+			buf.WriteString(theirsText)
+		default:
+			conflict = true
+			buf.WriteString("<<<<<<< ours\n")
+			buf.WriteString(withNewline(oursText))
+			buf.WriteString("=======\n")
+			buf.WriteString(withNewline(theirsText))
+			buf.WriteString(">>>>>>> theirs\n")
+		}
+	}
+	writeLines(&buf, baseLines[pos:])
+	result = buf.String()
+	return
+}
+
+func getMergeHunks(base, changed []string, ours bool) (hunks []mergeHunk) {
+	for _, op := range difflib.NewMatcher(base, changed).GetOpCodes() {
+		if op.Tag != 'e' {
+			hunks = append(hunks, mergeHunk{start: op.I1, end: op.I2, lines: changed[op.J1:op.J2], ours: ours})
+		}
+	}
+	return
+}
+
+// applyHunks returns the base lines in the range [start, end) with the changes of one side
+func applyHunks(base []string, start, end int, hunks []mergeHunk, ours bool) string {
+	var buf strings.Builder
+	pos := start
+	for _, hunk := range hunks {
+		if hunk.ours != ours {
+			continue
+		}
+		writeLines(&buf, base[pos:hunk.start])
+		writeLines(&buf, hunk.lines)
+		pos = hunk.end
+	}
+	writeLines(&buf, base[pos:end])
+	return buf.String()
+}
+
+func writeLines(buf *strings.Builder, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+}
+
+// splitLines splits the text into lines, the line breaks are kept
+func splitLines(text string) (lines []string) {
+	if lines = strings.SplitAfter(text, "\n"); lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return
+}
+
+// splitDiffLines splits the text into lines, all of them end with a line break
+func splitDiffLines(text string) (lines []string) {
+	if lines = splitLines(text); len(lines) > 0 {
+		lines[len(lines)-1] = withNewline(lines[len(lines)-1])
+	}
+	return
+}
+
+func withNewline(text string) string {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text
+}
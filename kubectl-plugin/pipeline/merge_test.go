@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Empty(t, unifiedDiff("Jenkinsfile", "a\nb\n", "a\nb\n"))
+	assert.Equal(t, `--- Jenkinsfile
++++ Jenkinsfile
+@@ -1,2 +1,2 @@
+ a
+-b
++c
+`, unifiedDiff("Jenkinsfile", "a\nb\n", "a\nc\n"))
+}
+
+func TestMergeText(t *testing.T) {
+	base := "pipeline {\n  agent any\n  stages {\n    stage('a') {}\n  }\n}\n"
+	tests := []struct {
+		name     string
+		ours     string
+		theirs   string
+		result   string
+		conflict bool
+	}{{
+		name:   "no changes of theirs",
+		ours:   "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n  }\n}\n",
+		theirs: base,
+		result: "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n  }\n}\n",
+	}, {
+		name:   "changes in different lines",
+		ours:   "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n  }\n}\n",
+		theirs: "pipeline {\n  agent any\n  stages {\n    stage('a') {}\n    stage('b') {}\n  }\n}\n",
+		result: "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n    stage('b') {}\n  }\n}\n",
+	}, {
+		name:   "same changes",
+		ours:   "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n  }\n}\n",
+		theirs: "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n  }\n}\n",
+		result: "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n  }\n}\n",
+	}, {
+		name:     "conflict",
+		ours:     "pipeline {\n  agent none\n  stages {\n    stage('a') {}\n  }\n}\n",
+		theirs:   "pipeline {\n  agent { label 'go' }\n  stages {\n    stage('a') {}\n  }\n}\n",
+		result:   "pipeline {\n<<<<<<< ours\n  agent none\n=======\n  agent { label 'go' }\n>>>>>>> theirs\n  stages {\n    stage('a') {}\n  }\n}\n",
+		conflict: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, conflict := mergeText(base, tt.ours, tt.theirs)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.conflict, conflict)
+		})
+	}
+}
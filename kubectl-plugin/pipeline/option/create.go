@@ -20,8 +20,10 @@ import (
 )
 
 const (
-	PipelinerunOwnerLabelKey   = "devops.kubesphere.io/pipeline"
-	PipelinerunIdAnnotationKey = "devops.kubesphere.io/jenkins-pipelinerun-id"
+	PipelinerunOwnerLabelKey             = "devops.kubesphere.io/pipeline"
+	PipelinerunIdAnnotationKey           = "devops.kubesphere.io/jenkins-pipelinerun-id"
+	PipelinerunStagesStatusAnnotationKey = "devops.kubesphere.io/jenkins-pipelinerun-stages-status"
+	PipelinerunStatusAnnotationKey       = "devops.kubesphere.io/jenkins-pipelinerun-status"
 
 	PipelinerunPhaseRunning   = "Running"
 	PipelinerunPhaseSucceeded = "Succeeded"
@@ -30,6 +32,10 @@ const (
 
 	NoScmPipelineType       = "pipeline"
 	MultiBranchPipelineType = "multi-branch-pipeline"
+
+	DevOpsProjectCreatorAnnotationKey     = "kubesphere.io/creator"
+	DevOpsProjectDescriptionAnnotationKey = "kubesphere.io/description"
+	DevOpsProjectWorkspaceLabelKey        = "kubesphere.io/workspace"
 )
 
 // PipelineCreateOption is the option for creating a pipeline
@@ -43,10 +49,48 @@ type PipelineCreateOption struct {
 	SCMType     string
 	Batch       bool
 	SkipCheck   bool
+	// Creator is the creator of the DevOps project, it's admin if it's empty
+	Creator string
+	// ProjectDescription is the description of the DevOps project
+	ProjectDescription string
+
+	// GitURL is the repository to read the Jenkinsfile from
+	GitURL string
+	// GitPath is the path of the Jenkinsfile in the git repository
+	GitPath string
+	// GitRef is the branch or tag of the git repository
+	GitRef string
+
+	// TemplateDir is the directory of the user-defined templates
+	TemplateDir string
+	// TemplateVariables are the values of the variables declared by a user-defined template
+	TemplateVariables map[string]string
+
+	// Pipeline settings, the zero values of DaysToKeep and NumToKeep mean the default ones
+	DaysToKeep  int
+	NumToKeep   int
+	Concurrent  bool
+	Description string
+
+	// SCM settings of the multi-branch Pipeline
+	// SCMRepo is the URL of a git repository, or the owner/repo of the other SCM types
+	SCMRepo  string
+	SCMOwner string
+	// SCMServer is the server of GitLab, Gitea, Bitbucket Server or GitHub Enterprise
+	SCMServer    string
+	CredentialID string
+	ScriptPath   string
+	// the discovery strategies, see GetDiscoverBranchesStrategies, GetDiscoverPRStrategies and GetDiscoverForkTrusts
+	DiscoverBranches     string
+	DiscoverPRFromOrigin string
+	DiscoverPRFromForks  string
+	DiscoverForkTrust    string
+	DiscoverTags         bool
 
 	// Inner fields
-	Client       dynamic.Interface
-	WorkspaceUID string
+	Client          dynamic.Interface
+	WorkspaceUID    string
+	customTemplates []tpl.Template
 }
 
 // Wizard is the wizard for creating a pipeline
@@ -78,14 +122,27 @@ func (o *PipelineCreateOption) Wizard(_ *cobra.Command, _ []string) (err error)
 		}
 	}
 
-	if o.Template == "" {
-		if o.Template, err = ChooseOneFromArray(tpl.GetAllTemplates()); err != nil {
+	if o.Template == "" && o.Jenkinsfile == "" && o.GitURL == "" {
+		if err = o.LoadTemplates(); err != nil {
+			return
+		}
+		if o.Template, err = ChooseOneFromArray(o.GetTemplateNames()); err != nil {
+			return
+		}
+	}
+
+	if o.Template != "" {
+		if err = o.askTemplateVariables(); err != nil {
 			return
 		}
 	}
 
 	if o.Name == "" {
-		defaultVal := fmt.Sprintf("%s-%s", o.Template, strings.ToLower(randomdata.SillyName()))
+		prefix := o.Template
+		if prefix == "" {
+			prefix = "pipeline"
+		}
+		defaultVal := fmt.Sprintf("%s-%s", prefix, strings.ToLower(randomdata.SillyName()))
 		if o.Name, err = getInput("Please input the Pipeline name", defaultVal); err != nil {
 			return
 		}
@@ -137,14 +194,29 @@ func (o *PipelineCreateOption) ParseTemplate() (err error) {
 	case "multi-branch-git":
 		o.Type = "multi-branch-pipeline"
 		o.SCMType = "git"
+		o.setDemoSCM()
 	case "multi-branch-gitlab":
 		o.Type = "multi-branch-pipeline"
 		o.SCMType = "gitlab"
+		o.setDemoSCM()
 	case "multi-branch-github":
 		o.Type = "multi-branch-pipeline"
 		o.SCMType = "github"
+		o.setDemoSCM()
 	default:
-		err = fmt.Errorf("%s is not support", o.Template)
+		var template *tpl.Template
+		if template, err = o.getCustomTemplate(o.Template); err != nil {
+			return
+		}
+		if template.Type != "" {
+			o.Type = template.Type
+		}
+		if template.SCMType != "" {
+			o.SCMType = template.SCMType
+		}
+		if o.Jenkinsfile, err = template.Render(o.TemplateVariables); err != nil {
+			return
+		}
 	}
 	o.Jenkinsfile = strings.TrimSpace(o.Jenkinsfile)
 	return
@@ -169,6 +241,9 @@ func (o *PipelineCreateOption) CreatePipeline() (err error) {
 		return
 	}
 	o.Project = project.GetName() // the previous name is a generated name
+	if err = o.CheckCredential(); err != nil {
+		return
+	}
 
 	var rawPip *unstructured.Unstructured
 	if rawPip, err = o.createPipelineObj(); err == nil {
@@ -310,6 +385,23 @@ func (o *PipelineCreateOption) getDevOpsProjectList() (wsList *unstructured.Unst
 	return
 }
 
+// GetDevOpsProject returns a DevOpsProject by its name or generateName
+func GetDevOpsProject(ctx context.Context, client dynamic.Interface, name string) (project *unstructured.Unstructured, err error) {
+	var list *unstructured.UnstructuredList
+	if list, err = client.Resource(types.GetDevOpsProjectSchema()).List(ctx, metav1.ListOptions{}); err != nil {
+		return
+	}
+
+	for i := range list.Items {
+		if item := list.Items[i]; item.GetName() == name || item.GetGenerateName() == name {
+			project = &item
+			return
+		}
+	}
+	err = fmt.Errorf("cannot find DevOps project '%s'", name)
+	return
+}
+
 // CheckDevOpsProject makes sure the project exist
 func (o *PipelineCreateOption) CheckDevOpsProject(wsID string) (project *unstructured.Unstructured, err error) {
 	ctx := context.TODO()
@@ -345,6 +437,14 @@ func (o *PipelineCreateOption) CheckDevOpsProject(wsID string) (project *unstruc
 			err = fmt.Errorf("failed to unmarshal yaml to DevOpsProject object, %v", err)
 			return
 		}
+		annotations := projectObj.GetAnnotations()
+		if o.Creator != "" {
+			annotations[DevOpsProjectCreatorAnnotationKey] = o.Creator
+		}
+		if o.ProjectDescription != "" {
+			annotations[DevOpsProjectDescriptionAnnotationKey] = o.ProjectDescription
+		}
+		projectObj.SetAnnotations(annotations)
 
 		if project, err = o.Client.Resource(types.GetDevOpsProjectSchema()).Create(ctx, projectObj, metav1.CreateOptions{}); err != nil {
 			err = fmt.Errorf("failed to create devops project with YAML: '%s'. Error is: %v", buf.String(), err)
@@ -374,6 +474,15 @@ func (o *PipelineCreateOption) createPipelineObj() (rawPip *unstructured.Unstruc
 
 	if rawPip, err = types.GetObjectFromYaml(buf.String()); err != nil {
 		err = fmt.Errorf("failed to unmarshal yaml to Pipeline object, %v", err)
+		return
+	}
+
+	if o.Type == MultiBranchPipelineType {
+		var sourceKey string
+		var source map[string]interface{}
+		if sourceKey, source, err = o.getSCMSource(); err == nil {
+			err = unstructured.SetNestedMap(rawPip.Object, source, "spec", "multi_branch_pipeline", sourceKey)
+		}
 	}
 	return
 }
@@ -413,46 +522,26 @@ metadata:
 spec:
   {{if eq .Type "pipeline"}}
   pipeline:
-    disable_concurrent: true
+    {{- if .Description}}
+    description: {{.Description | quote | raw}}
+    {{- end}}
+    disable_concurrent: {{not .Concurrent}}
     discarder:
-      days_to_keep: "7"
-      num_to_keep: "10"
+      days_to_keep: "{{.DaysToKeep | default 7}}"
+      num_to_keep: "{{.NumToKeep | default 10}}"
     jenkinsfile: |
 {{.Jenkinsfile | indent 6 | raw}}
     name: "{{.Name}}"
   {{else if eq .Type "multi-branch-pipeline" -}}
   multi_branch_pipeline:
+    {{- if .Description}}
+    description: {{.Description | quote | raw}}
+    {{- end}}
     discarder:
       days_to_keep: "-1"
       num_to_keep: "-1"
-    {{if eq .SCMType "gitlab"}}
-    gitlab_source:
-      discover_branches: 1
-      discover_pr_from_forks:
-        strategy: 2
-        trust: 2
-      discover_pr_from_origin: 2
-      discover_tags: true
-      owner: devops-ws
-      repo: devops-ws/learn-pipeline-java
-      server_name: https://gitlab.com
-    {{else if eq .SCMType "github" -}}
-    github_source:
-      discover_branches: 1
-      discover_pr_from_forks:
-        strategy: 2
-        trust: 2
-      discover_pr_from_origin: 2
-      discover_tags: true
-      owner: devops-ws
-      repo: learn-pipeline-java
-    {{else if eq .SCMType "git" -}}
-    git_source:
-      discover_branches: true
-      url: https://gitee.com/devops-ws/learn-pipeline-java
-    {{end -}}
     name: "{{.Name}}"
-    script_path: Jenkinsfile
+    script_path: "{{.ScriptPath | default "Jenkinsfile"}}"
     source_type: {{.SCMType}}
   {{end -}}
   type: {{.Type}}
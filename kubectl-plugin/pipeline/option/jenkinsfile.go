@@ -0,0 +1,78 @@
+package option
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// LoadJenkinsfile loads the Jenkinsfile from a git repository or a local file.
+// The Jenkinsfile is taken as the content if it's not an existing file.
+func (o *PipelineCreateOption) LoadJenkinsfile() (err error) {
+	if o.GitURL != "" {
+		o.Jenkinsfile, err = ReadFileFromGit(o.GitURL, o.GitRef, o.GitPath)
+		return
+	}
+
+	if o.Jenkinsfile == "" {
+		return
+	}
+	if info, statErr := os.Stat(o.Jenkinsfile); statErr == nil && !info.IsDir() {
+		var data []byte
+		if data, err = ioutil.ReadFile(o.Jenkinsfile); err != nil {
+			err = fmt.Errorf("failed to read Jenkinsfile %s, error: %v", o.Jenkinsfile, err)
+			return
+		}
+		o.Jenkinsfile = string(data)
+	}
+	return
+}
+
+// ReadFileFromGit reads a file from a git repository in memory, the ref could be a branch or a tag
+func ReadFileFromGit(url, ref, file string) (content string, err error) {
+	if file == "" {
+		file = "Jenkinsfile"
+	}
+
+	var repo *git.Repository
+	cloneOpt := &git.CloneOptions{
+		URL:          url,
+		SingleBranch: true,
+		Depth:        1,
+	}
+	if ref == "" {
+		repo, err = git.Clone(memory.NewStorage(), memfs.New(), cloneOpt)
+	} else {
+		cloneOpt.ReferenceName = plumbing.NewBranchReferenceName(ref)
+		if repo, err = git.Clone(memory.NewStorage(), memfs.New(), cloneOpt); err != nil {
+			cloneOpt.ReferenceName = plumbing.NewTagReferenceName(ref)
+			repo, err = git.Clone(memory.NewStorage(), memfs.New(), cloneOpt)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to clone git repository %s, error: %v", url, err)
+		return
+	}
+
+	var worktree *git.Worktree
+	if worktree, err = repo.Worktree(); err != nil {
+		return
+	}
+
+	var data []byte
+	if f, openErr := worktree.Filesystem.Open(file); openErr == nil {
+		defer func() {
+			_ = f.Close()
+		}()
+		data, err = ioutil.ReadAll(f)
+	} else {
+		err = fmt.Errorf("failed to read %s from git repository %s, error: %v", file, url, openErr)
+	}
+	content = string(data)
+	return
+}
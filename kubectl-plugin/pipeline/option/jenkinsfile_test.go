@@ -0,0 +1,105 @@
+package option
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestLoadJenkinsfileFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jenkinsfile")
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	file := filepath.Join(dir, "Jenkinsfile")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("pipeline {}"), 0644))
+
+	opt := &PipelineCreateOption{Jenkinsfile: file}
+	assert.Nil(t, opt.LoadJenkinsfile())
+	assert.Equal(t, "pipeline {}", opt.Jenkinsfile)
+
+	// take it as the content if the file does not exist
+	opt = &PipelineCreateOption{Jenkinsfile: "pipeline { agent any }"}
+	assert.Nil(t, opt.LoadJenkinsfile())
+	assert.Equal(t, "pipeline { agent any }", opt.Jenkinsfile)
+}
+
+func TestLoadJenkinsfileFromGit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jenkinsfile-repo")
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	repo, err := git.PlainInit(dir, false)
+	assert.Nil(t, err)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "ci"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "ci", "Jenkinsfile"), []byte("pipeline {}"), 0644))
+
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+	_, err = worktree.Add("ci/Jenkinsfile")
+	assert.Nil(t, err)
+	commit, err := worktree.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "ks", Email: "ks@example.com", When: time.Now()},
+	})
+	assert.Nil(t, err)
+	_, err = repo.CreateTag("v1.0.0", commit, nil)
+	assert.Nil(t, err)
+
+	opt := &PipelineCreateOption{GitURL: dir, GitPath: "ci/Jenkinsfile"}
+	assert.Nil(t, opt.LoadJenkinsfile())
+	assert.Equal(t, "pipeline {}", opt.Jenkinsfile)
+
+	// read it from a tag
+	opt = &PipelineCreateOption{GitURL: dir, GitPath: "ci/Jenkinsfile", GitRef: "v1.0.0"}
+	assert.Nil(t, opt.LoadJenkinsfile())
+	assert.Equal(t, "pipeline {}", opt.Jenkinsfile)
+
+	// the file does not exist
+	opt = &PipelineCreateOption{GitURL: dir}
+	assert.NotNil(t, opt.LoadJenkinsfile())
+
+	// the ref does not exist
+	opt = &PipelineCreateOption{GitURL: dir, GitPath: "ci/Jenkinsfile", GitRef: "fake"}
+	assert.NotNil(t, opt.LoadJenkinsfile())
+}
+
+func TestCreatePipelineObjWithSettings(t *testing.T) {
+	opt := &PipelineCreateOption{
+		Name:        "demo",
+		Project:     "ns",
+		Type:        NoScmPipelineType,
+		Jenkinsfile: "pipeline {}",
+		Description: `a "demo" <pipeline>`,
+	}
+	obj, err := opt.createPipelineObj()
+	assert.Nil(t, err)
+	days, _, _ := unstructured.NestedString(obj.Object, "spec", "pipeline", "discarder", "days_to_keep")
+	assert.Equal(t, "7", days)
+	num, _, _ := unstructured.NestedString(obj.Object, "spec", "pipeline", "discarder", "num_to_keep")
+	assert.Equal(t, "10", num)
+	description, _, _ := unstructured.NestedString(obj.Object, "spec", "pipeline", "description")
+	assert.Equal(t, `a "demo" <pipeline>`, description)
+	disabled, _, _ := unstructured.NestedBool(obj.Object, "spec", "pipeline", "disable_concurrent")
+	assert.True(t, disabled)
+
+	opt.DaysToKeep, opt.NumToKeep, opt.Concurrent, opt.Description = 3, 5, true, ""
+	obj, err = opt.createPipelineObj()
+	assert.Nil(t, err)
+	days, _, _ = unstructured.NestedString(obj.Object, "spec", "pipeline", "discarder", "days_to_keep")
+	assert.Equal(t, "3", days)
+	num, _, _ = unstructured.NestedString(obj.Object, "spec", "pipeline", "discarder", "num_to_keep")
+	assert.Equal(t, "5", num)
+	disabled, _, _ = unstructured.NestedBool(obj.Object, "spec", "pipeline", "disable_concurrent")
+	assert.False(t, disabled)
+}
@@ -0,0 +1,227 @@
+package option
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The SCM types of the multi-branch Pipeline
+const (
+	SCMTypeGit             = "git"
+	SCMTypeGitHub          = "github"
+	SCMTypeGitLab          = "gitlab"
+	SCMTypeGitea           = "gitea"
+	SCMTypeBitbucketServer = "bitbucket_server"
+)
+
+// defaultGitLabServer is the server of GitLab when it's not given
+const defaultGitLabServer = "https://gitlab.com"
+
+var discoverBranchesStrategies = map[string]int64{
+	"exclude-pr": 1,
+	"only-pr":    2,
+	"all":        3,
+}
+
+var discoverPRStrategies = map[string]int64{
+	"none":  0,
+	"merge": 1,
+	"head":  2,
+	"both":  3,
+}
+
+var discoverForkTrusts = map[string]int64{
+	"contributors": 1,
+	"everyone":     2,
+	"permission":   3,
+	"nobody":       4,
+}
+
+// GetSCMTypes returns the supported SCM types of the multi-branch Pipeline
+func GetSCMTypes() []string {
+	return []string{SCMTypeGit, SCMTypeGitHub, SCMTypeGitLab, SCMTypeGitea, SCMTypeBitbucketServer}
+}
+
+// GetDiscoverBranchesStrategies returns the strategies of discovering branches
+func GetDiscoverBranchesStrategies() []string {
+	return getSortedKeys(discoverBranchesStrategies)
+}
+
+// GetDiscoverPRStrategies returns the strategies of discovering pull requests
+func GetDiscoverPRStrategies() []string {
+	return getSortedKeys(discoverPRStrategies)
+}
+
+// GetDiscoverForkTrusts returns the trust levels of the pull requests from forks
+func GetDiscoverForkTrusts() []string {
+	return getSortedKeys(discoverForkTrusts)
+}
+
+func getSortedKeys(items map[string]int64) (keys []string) {
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+// setDemoSCM sets the demo repository of the built-in multi-branch templates if the repository is not given
+func (o *PipelineCreateOption) setDemoSCM() {
+	if o.SCMRepo != "" {
+		return
+	}
+
+	switch o.SCMType {
+	case SCMTypeGit:
+		o.SCMRepo = "https://gitee.com/devops-ws/learn-pipeline-java"
+	case SCMTypeGitLab:
+		o.SCMOwner, o.SCMRepo = "devops-ws", "devops-ws/learn-pipeline-java"
+	case SCMTypeGitHub:
+		o.SCMOwner, o.SCMRepo = "devops-ws", "learn-pipeline-java"
+	}
+}
+
+// getSCMSource returns the SCM source of the multi-branch Pipeline, the key is like: github_source
+func (o *PipelineCreateOption) getSCMSource() (key string, source map[string]interface{}, err error) {
+	if o.SCMRepo == "" {
+		err = fmt.Errorf("please provide the repository of the multi-branch Pipeline via --repo")
+		return
+	}
+
+	source = map[string]interface{}{}
+	if o.CredentialID != "" {
+		source["credential_id"] = o.CredentialID
+	}
+
+	switch o.SCMType {
+	case SCMTypeGit:
+		source["url"] = o.SCMRepo
+		source["discover_branches"] = true
+		source["discover_tags"] = o.DiscoverTags
+	case SCMTypeGitHub, SCMTypeGitLab, SCMTypeGitea, SCMTypeBitbucketServer:
+		owner, repo := o.SCMOwner, o.SCMRepo
+		if index := strings.Index(repo, "/"); index > 0 && owner == "" {
+			owner = repo[:index]
+		}
+		if owner == "" {
+			err = fmt.Errorf("please provide the owner of the repository via --owner or --repo owner/repo")
+			return
+		}
+		if o.SCMType == SCMTypeGitLab {
+			// the repository of GitLab is the full path
+			if !strings.Contains(repo, "/") {
+				repo = owner + "/" + repo
+			}
+		} else {
+			repo = strings.TrimPrefix(repo, owner+"/")
+		}
+		source["owner"] = owner
+		source["repo"] = repo
+
+		switch o.SCMType {
+		case SCMTypeGitHub:
+			if o.SCMServer != "" {
+				source["api_uri"] = o.SCMServer
+			}
+		case SCMTypeGitLab:
+			source["server_name"] = o.SCMServer
+			if o.SCMServer == "" {
+				source["server_name"] = defaultGitLabServer
+			}
+		case SCMTypeGitea:
+			if err = validateServerURL(o.SCMServer, "Gitea"); err != nil {
+				return
+			}
+			source["server_name"] = o.SCMServer
+		case SCMTypeBitbucketServer:
+			if o.SCMServer == "" {
+				err = fmt.Errorf("please provide the server of Bitbucket Server via --server")
+				return
+			}
+			source["api_uri"] = o.SCMServer
+		}
+
+		if err = o.setDiscoverStrategies(source); err != nil {
+			return
+		}
+	default:
+		err = fmt.Errorf("not supported SCM type: '%s', supported types: %s", o.SCMType,
+			strings.Join(GetSCMTypes(), ", "))
+		return
+	}
+	key = o.SCMType + "_source"
+	return
+}
+
+func (o *PipelineCreateOption) setDiscoverStrategies(source map[string]interface{}) (err error) {
+	branches, ok := discoverBranchesStrategies[defaultString(o.DiscoverBranches, "exclude-pr")]
+	if !ok {
+		err = fmt.Errorf("not supported discover branches strategy: %s", o.DiscoverBranches)
+		return
+	}
+	source["discover_branches"] = branches
+
+	var origin, forks, trust int64
+	if origin, ok = discoverPRStrategies[defaultString(o.DiscoverPRFromOrigin, "head")]; !ok {
+		err = fmt.Errorf("not supported discover pull requests strategy: %s", o.DiscoverPRFromOrigin)
+		return
+	}
+	if origin > 0 {
+		source["discover_pr_from_origin"] = origin
+	}
+
+	if forks, ok = discoverPRStrategies[defaultString(o.DiscoverPRFromForks, "head")]; !ok {
+		err = fmt.Errorf("not supported discover pull requests strategy: %s", o.DiscoverPRFromForks)
+		return
+	}
+	if trust, ok = discoverForkTrusts[defaultString(o.DiscoverForkTrust, "everyone")]; !ok {
+		err = fmt.Errorf("not supported trust of the pull requests from forks: %s", o.DiscoverForkTrust)
+		return
+	}
+	if forks > 0 {
+		source["discover_pr_from_forks"] = map[string]interface{}{
+			"strategy": forks,
+			"trust":    trust,
+		}
+	}
+	source["discover_tags"] = o.DiscoverTags
+	return
+}
+
+// CheckCredential makes sure the credential exists in the DevOps project
+func (o *PipelineCreateOption) CheckCredential() (err error) {
+	if o.CredentialID == "" {
+		return
+	}
+	if _, err = o.Client.Resource(types.GetSecretSchema()).Namespace(o.Project).Get(context.TODO(), o.CredentialID,
+		metav1.GetOptions{}); err != nil {
+		err = fmt.Errorf("cannot find credential '%s' in DevOps project '%s', error: %v", o.CredentialID, o.Project, err)
+	}
+	return
+}
+
+// validateServerURL makes sure the server of a self-hosted SCM is an HTTP or HTTPS URL
+func validateServerURL(server, scm string) (err error) {
+	if server == "" {
+		err = fmt.Errorf("please provide the server of %s via --server", scm)
+		return
+	}
+	if serverURL, parseErr := url.Parse(server); parseErr != nil || serverURL.Host == "" ||
+		(serverURL.Scheme != "http" && serverURL.Scheme != "https") {
+		err = fmt.Errorf("the server of %s should be an HTTP or HTTPS URL, such as: https://gitea.com, got: %s", scm, server)
+	}
+	return
+}
+
+func defaultString(val, defaultVal string) string {
+	if val == "" {
+		return defaultVal
+	}
+	return val
+}
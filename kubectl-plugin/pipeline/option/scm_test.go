@@ -0,0 +1,121 @@
+package option
+
+import (
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestCreateMultiBranchPipelineObj(t *testing.T) {
+	opt := &PipelineCreateOption{
+		Name:         "demo",
+		Project:      "ns",
+		Type:         MultiBranchPipelineType,
+		SCMType:      SCMTypeGitHub,
+		SCMRepo:      "owner/repo",
+		CredentialID: "github-token",
+		ScriptPath:   "ci/Jenkinsfile",
+		DiscoverTags: true,
+	}
+	obj, err := opt.createPipelineObj()
+	assert.Nil(t, err)
+	source, _, _ := unstructured.NestedMap(obj.Object, "spec", "multi_branch_pipeline", "github_source")
+	assert.Equal(t, map[string]interface{}{
+		"owner":                   "owner",
+		"repo":                    "repo",
+		"credential_id":           "github-token",
+		"discover_branches":       int64(1),
+		"discover_pr_from_origin": int64(2),
+		"discover_pr_from_forks": map[string]interface{}{
+			"strategy": int64(2),
+			"trust":    int64(2),
+		},
+		"discover_tags": true,
+	}, source)
+	scriptPath, _, _ := unstructured.NestedString(obj.Object, "spec", "multi_branch_pipeline", "script_path")
+	assert.Equal(t, "ci/Jenkinsfile", scriptPath)
+	sourceType, _, _ := unstructured.NestedString(obj.Object, "spec", "multi_branch_pipeline", "source_type")
+	assert.Equal(t, SCMTypeGitHub, sourceType)
+
+	// the repository of GitLab is the full path
+	opt.SCMType, opt.SCMRepo, opt.SCMOwner = SCMTypeGitLab, "repo", "group"
+	opt.DiscoverBranches, opt.DiscoverPRFromForks = "all", "none"
+	key, source, err := opt.getSCMSource()
+	assert.Nil(t, err)
+	assert.Equal(t, "gitlab_source", key)
+	assert.Equal(t, "group/repo", source["repo"])
+	assert.Equal(t, defaultGitLabServer, source["server_name"])
+	assert.Equal(t, int64(3), source["discover_branches"])
+	assert.NotContains(t, source, "discover_pr_from_forks")
+
+	opt.SCMType, opt.SCMRepo, opt.SCMOwner = SCMTypeBitbucketServer, "project/repo", ""
+	opt.SCMServer = "https://bitbucket.example.com"
+	key, source, err = opt.getSCMSource()
+	assert.Nil(t, err)
+	assert.Equal(t, "bitbucket_server_source", key)
+	assert.Equal(t, "project", source["owner"])
+	assert.Equal(t, "repo", source["repo"])
+	assert.Equal(t, "https://bitbucket.example.com", source["api_uri"])
+
+	opt.SCMType, opt.SCMServer = SCMTypeGitea, "https://gitea.example.com"
+	key, source, err = opt.getSCMSource()
+	assert.Nil(t, err)
+	assert.Equal(t, "gitea_source", key)
+	assert.Equal(t, "project", source["owner"])
+	assert.Equal(t, "repo", source["repo"])
+	assert.Equal(t, "https://gitea.example.com", source["server_name"])
+	assert.Equal(t, int64(3), source["discover_branches"])
+
+	opt.SCMType, opt.SCMRepo = SCMTypeGit, "https://gitee.com/owner/repo"
+	_, source, err = opt.getSCMSource()
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gitee.com/owner/repo", source["url"])
+	assert.Equal(t, true, source["discover_branches"])
+
+	// invalid settings
+	for _, invalid := range []PipelineCreateOption{
+		{SCMType: SCMTypeGitHub},
+		{SCMType: SCMTypeGitHub, SCMRepo: "repo"},
+		{SCMType: SCMTypeBitbucketServer, SCMRepo: "owner/repo"},
+		{SCMType: SCMTypeGitHub, SCMRepo: "owner/repo", DiscoverBranches: "fake"},
+		{SCMType: SCMTypeGitHub, SCMRepo: "owner/repo", DiscoverForkTrust: "fake"},
+		{SCMType: "svn", SCMRepo: "owner/repo"},
+		{SCMType: SCMTypeGitea, SCMRepo: "owner/repo"},
+		{SCMType: SCMTypeGitea, SCMRepo: "owner/repo", SCMServer: "gitea.example.com"},
+		{SCMType: SCMTypeGitea, SCMRepo: "owner/repo", SCMServer: "ftp://gitea.example.com"},
+	} {
+		_, _, err = invalid.getSCMSource()
+		assert.NotNil(t, err, invalid)
+	}
+
+	// the built-in templates create the demo Pipelines
+	opt = &PipelineCreateOption{Template: "multi-branch-github"}
+	assert.Nil(t, opt.ParseTemplate())
+	assert.Equal(t, "devops-ws", opt.SCMOwner)
+	opt = &PipelineCreateOption{Template: "multi-branch-github", SCMRepo: "owner/repo"}
+	assert.Nil(t, opt.ParseTemplate())
+	assert.Empty(t, opt.SCMOwner)
+}
+
+func TestCheckCredential(t *testing.T) {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace("ns")
+	secret.SetName("github-token")
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		types.GetSecretSchema(): "SecretList",
+	}, secret)
+
+	opt := &PipelineCreateOption{Client: client, Project: "ns"}
+	assert.Nil(t, opt.CheckCredential())
+	opt.CredentialID = "github-token"
+	assert.Nil(t, opt.CheckCredential())
+	opt.CredentialID = "fake"
+	assert.NotNil(t, opt.CheckCredential())
+}
@@ -0,0 +1,132 @@
+package option
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/tpl"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// PipelineTemplateLabelKey is the label of the ConfigMaps which hold the Pipeline templates,
+// each item of the ConfigMap data is a template
+const PipelineTemplateLabelKey = "devops.kubesphere.io/pipeline-template"
+
+// LoadTemplates loads the user-defined templates from the template directory and the ConfigMaps
+func (o *PipelineCreateOption) LoadTemplates() (err error) {
+	var templates []tpl.Template
+	if o.TemplateDir != "" {
+		if templates, err = tpl.LoadTemplatesFromDir(o.TemplateDir); err != nil {
+			return
+		}
+	}
+
+	// the templates in the cluster are optional, only warn about the errors when listing them
+	if o.Client != nil {
+		clusterTemplates, clusterErr := getTemplatesFromConfigMaps(o.Client)
+		if clusterErr != nil {
+			log.Warnf("failed to load the Pipeline templates from ConfigMaps, error: %v", clusterErr)
+		}
+		templates = append(templates, clusterTemplates...)
+	}
+	o.customTemplates = templates
+	return
+}
+
+// getTemplatesFromConfigMaps returns the templates in the ConfigMaps, the invalid ones are skipped with a warning
+func getTemplatesFromConfigMaps(client dynamic.Interface) (templates []tpl.Template, err error) {
+	var list *unstructured.UnstructuredList
+	if list, err = client.Resource(types.GetConfigMapSchema()).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true", PipelineTemplateLabelKey),
+	}); err != nil {
+		return
+	}
+
+	for _, item := range list.Items {
+		data, _, _ := unstructured.NestedStringMap(item.Object, "data")
+		for key, val := range data {
+			template, parseErr := tpl.ParseTemplate([]byte(val))
+			if parseErr != nil {
+				log.Warnf("failed to parse template %s in ConfigMap %s/%s, skipped. error: %v",
+					key, item.GetNamespace(), item.GetName(), parseErr)
+				continue
+			}
+			templates = append(templates, *template)
+		}
+	}
+	return
+}
+
+// GetTemplateNames returns the names of the built-in and user-defined templates
+func (o *PipelineCreateOption) GetTemplateNames() (names []string) {
+	names = tpl.GetAllTemplates()
+	for _, template := range o.customTemplates {
+		if !contains(names, template.Name) {
+			names = append(names, template.Name)
+		}
+	}
+	return
+}
+
+func (o *PipelineCreateOption) getCustomTemplate(name string) (template *tpl.Template, err error) {
+	if o.customTemplates == nil {
+		if err = o.LoadTemplates(); err != nil {
+			return
+		}
+	}
+
+	for i := range o.customTemplates {
+		if o.customTemplates[i].Name == name {
+			template = &o.customTemplates[i]
+			return
+		}
+	}
+	err = fmt.Errorf("%s is not support", name)
+	return
+}
+
+// askTemplateVariables asks for the variables of a user-defined template which are not given
+func (o *PipelineCreateOption) askTemplateVariables() (err error) {
+	if contains(tpl.GetAllTemplates(), o.Template) {
+		return
+	}
+
+	var template *tpl.Template
+	if template, err = o.getCustomTemplate(o.Template); err != nil {
+		return
+	}
+
+	if o.TemplateVariables == nil {
+		o.TemplateVariables = map[string]string{}
+	}
+	for _, variable := range template.Variables {
+		if _, ok := o.TemplateVariables[variable.Name]; ok {
+			continue
+		}
+
+		var val string
+		if err = survey.AskOne(&survey.Input{
+			Message: variable.Name,
+			Default: variable.Default,
+			Help:    variable.Description,
+		}, &val); err != nil {
+			return
+		}
+		o.TemplateVariables[variable.Name] = val
+	}
+	return
+}
+
+func contains(items []string, item string) bool {
+	for _, val := range items {
+		if val == item {
+			return true
+		}
+	}
+	return false
+}
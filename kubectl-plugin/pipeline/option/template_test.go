@@ -0,0 +1,76 @@
+package option
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseCustomTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "echo.yaml"), []byte(`name: echo
+variables:
+- name: message
+  default: hello
+jenkinsfile: |
+  echo '{{.message}}'`), 0644))
+
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace("kubesphere-devops-system")
+	configMap.SetName("templates")
+	configMap.SetLabels(map[string]string{PipelineTemplateLabelKey: "true"})
+	configMap.Object["data"] = map[string]interface{}{
+		"github.yaml": `name: team-github
+type: multi-branch-pipeline
+scmType: github`,
+		"broken.yaml": `name: [broken`,
+	}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		types.GetConfigMapSchema(): "ConfigMapList",
+	}, configMap)
+
+	logs := bytes.NewBuffer(nil)
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	// the invalid template is skipped, and the others are kept
+	opt := &PipelineCreateOption{TemplateDir: dir, Client: client}
+	assert.Nil(t, opt.LoadTemplates())
+	assert.Contains(t, logs.String(), "failed to parse template broken.yaml in ConfigMap kubesphere-devops-system/templates")
+	names := opt.GetTemplateNames()
+	assert.Contains(t, names, "java")
+	assert.Contains(t, names, "echo")
+	assert.Contains(t, names, "team-github")
+
+	opt.Template = "echo"
+	assert.Nil(t, opt.ParseTemplate())
+	assert.Equal(t, "echo 'hello'", opt.Jenkinsfile)
+
+	opt.TemplateVariables = map[string]string{"message": "world"}
+	assert.Nil(t, opt.ParseTemplate())
+	assert.Equal(t, "echo 'world'", opt.Jenkinsfile)
+
+	opt.Template = "team-github"
+	assert.Nil(t, opt.ParseTemplate())
+	assert.Equal(t, MultiBranchPipelineType, opt.Type)
+	assert.Equal(t, "github", opt.SCMType)
+
+	opt.Template = "fake"
+	assert.NotNil(t, opt.ParseTemplate())
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const (
+	outputFormatJSON     = "json"
+	outputFormatYAML     = "yaml"
+	outputFormatWide     = "wide"
+	outputFormatName     = "name"
+	outputFormatJSONPath = "jsonpath="
+)
+
+// isObjectOutputFormat checks if the objects are printed as they are instead of a table
+func isObjectOutputFormat(format string) bool {
+	return format == outputFormatJSON || format == outputFormatYAML || format == outputFormatName ||
+		strings.HasPrefix(format, outputFormatJSONPath)
+}
+
+func getObjectPrinter(format string) (printer printers.ResourcePrinter, err error) {
+	switch {
+	case format == outputFormatJSON:
+		printer = &printers.JSONPrinter{}
+	case format == outputFormatYAML:
+		printer = &printers.YAMLPrinter{}
+	case format == outputFormatName:
+		printer = &printers.NamePrinter{}
+	case strings.HasPrefix(format, outputFormatJSONPath):
+		var jsonPathPrinter *printers.JSONPathPrinter
+		if jsonPathPrinter, err = printers.NewJSONPathPrinter(strings.TrimPrefix(format, outputFormatJSONPath)); err == nil {
+			jsonPathPrinter.AllowMissingKeys(true)
+			printer = jsonPathPrinter
+		}
+	default:
+		err = fmt.Errorf("not supported output format: %s", format)
+	}
+	return
+}
+
+// printObjects prints the Kubernetes objects as a list in JSON, YAML, name or JSONPath format
+func printObjects(w io.Writer, format string, items []unstructured.Unstructured) (err error) {
+	var printer printers.ResourcePrinter
+	if printer, err = getObjectPrinter(format); err != nil {
+		return
+	}
+
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+	}}
+	for i := range items {
+		item := items[i].DeepCopy()
+		item.SetManagedFields(nil)
+		list.Items = append(list.Items, *item)
+	}
+	err = printer.PrintObj(list, w)
+	return
+}
+
+// printObject prints a Kubernetes object in JSON, YAML, name or JSONPath format
+func printObject(w io.Writer, format string, item *unstructured.Unstructured) (err error) {
+	var printer printers.ResourcePrinter
+	if printer, err = getObjectPrinter(format); err == nil {
+		item = item.DeepCopy()
+		item.SetManagedFields(nil)
+		err = printer.PrintObj(item, w)
+	}
+	return
+}
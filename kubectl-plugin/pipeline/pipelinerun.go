@@ -0,0 +1,201 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// maskedValue replaces the sensitive values in the output
+const maskedValue = "******"
+
+// getPipelineRunList returns the PipelineRuns of a Pipeline, the newest one comes first
+func getPipelineRunList(ctx context.Context, client dynamic.Interface, ns, pipeline string) (items []unstructured.Unstructured, err error) {
+	var list *unstructured.UnstructuredList
+	if list, err = client.Resource(types.GetPipelineRunSchema()).Namespace(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", option.PipelinerunOwnerLabelKey, pipeline),
+	}); err != nil {
+		err = fmt.Errorf("failed to get PipelineRun list of %s/%s, error: %v", ns, pipeline, err)
+		return
+	}
+
+	items = list.Items
+	descOrderWithCreationTime(items)
+	return
+}
+
+// getLatestPipelineRun returns the newest PipelineRun of a Pipeline
+func getLatestPipelineRun(ctx context.Context, client dynamic.Interface, ns, pipeline string) (
+	pipelineRun *unstructured.Unstructured, err error) {
+	var items []unstructured.Unstructured
+	if items, err = getPipelineRunList(ctx, client, ns, pipeline); err == nil {
+		if len(items) == 0 {
+			err = fmt.Errorf("no PipelineRuns found of Pipeline %s/%s", ns, pipeline)
+		} else {
+			pipelineRun = &items[0]
+		}
+	}
+	return
+}
+
+func descOrderWithCreationTime(items []unstructured.Unstructured) {
+	sort.SliceStable(items, func(i, j int) bool {
+		left := items[i].GetCreationTimestamp()
+		right := items[j].GetCreationTimestamp()
+		if left.Equal(&right) {
+			return items[i].GetName() > items[j].GetName()
+		}
+		return right.Before(&left)
+	})
+}
+
+// getPipelineRunPhase returns the phase of a PipelineRun, it's empty if the PipelineRun is not handled yet
+func getPipelineRunPhase(pipelineRun *unstructured.Unstructured) (phase string) {
+	phase, _, _ = unstructured.NestedString(pipelineRun.Object, "status", "phase")
+	return
+}
+
+func isCompletedPhase(phase string) bool {
+	return phase == option.PipelinerunPhaseSucceeded || phase == option.PipelinerunPhaseFailed ||
+		phase == option.PipelinerunPhaseCancelled
+}
+
+// getPipelineRunBranch returns the SCM reference name of a multi-branch PipelineRun
+func getPipelineRunBranch(pipelineRun *unstructured.Unstructured) (branch string) {
+	branch, _, _ = unstructured.NestedString(pipelineRun.Object, "spec", "scm", "refName")
+	return
+}
+
+// getPipelineRunPipeline returns the name of the Pipeline which the PipelineRun belongs to
+func getPipelineRunPipeline(pipelineRun *unstructured.Unstructured) (pipeline string) {
+	if pipeline, _, _ = unstructured.NestedString(pipelineRun.Object, "spec", "pipelineRef", "name"); pipeline == "" {
+		pipeline = pipelineRun.GetLabels()[option.PipelinerunOwnerLabelKey]
+	}
+	return
+}
+
+// getPipelineRunStartTime returns the start time of a PipelineRun, it's the creation time if the PipelineRun is not started
+func getPipelineRunStartTime(pipelineRun *unstructured.Unstructured) time.Time {
+	if startTime, _, _ := unstructured.NestedString(pipelineRun.Object, "status", "startTime"); startTime != "" {
+		if result, err := time.Parse(time.RFC3339, startTime); err == nil {
+			return result
+		}
+	}
+	return pipelineRun.GetCreationTimestamp().Time
+}
+
+// getPipelineRunDuration returns the duration of a PipelineRun, it's the elapsed time if the PipelineRun is not completed
+func getPipelineRunDuration(pipelineRun *unstructured.Unstructured) time.Duration {
+	endTime, err := getCompletionTimeFromObject(pipelineRun.Object)
+	if err != nil {
+		endTime = time.Now()
+	}
+	return endTime.Sub(getPipelineRunStartTime(pipelineRun))
+}
+
+// jenkinsRunStatus is part of the Jenkins run which is stored in the annotation of a PipelineRun
+type jenkinsRunStatus struct {
+	CommitID string `json:"commitId"`
+	Causes   []struct {
+		ShortDescription string `json:"shortDescription"`
+	} `json:"causes"`
+}
+
+func getJenkinsRunStatus(pipelineRun *unstructured.Unstructured) (status *jenkinsRunStatus) {
+	status = &jenkinsRunStatus{}
+	_ = json.Unmarshal([]byte(pipelineRun.GetAnnotations()[option.PipelinerunStatusAnnotationKey]), status)
+	return
+}
+
+// getPipelineRunCause returns the trigger cause of a PipelineRun
+func getPipelineRunCause(pipelineRun *unstructured.Unstructured) string {
+	status := getJenkinsRunStatus(pipelineRun)
+	causes := make([]string, 0, len(status.Causes))
+	for _, cause := range status.Causes {
+		causes = append(causes, cause.ShortDescription)
+	}
+	if len(causes) == 0 {
+		if creator := pipelineRun.GetAnnotations()["kubesphere.io/creator"]; creator != "" {
+			causes = append(causes, fmt.Sprintf("Started by user %s", creator))
+		}
+	}
+	return strings.Join(causes, ", ")
+}
+
+// getPipelineRunParameters returns the parameters of a PipelineRun in the form of name=value,
+// the values of the password parameters are masked
+func getPipelineRunParameters(pipelineRun *unstructured.Unstructured, passwords map[string]bool) (params []string) {
+	items, _, _ := unstructured.NestedSlice(pipelineRun.Object, "spec", "parameters")
+	for _, item := range items {
+		if param, ok := item.(map[string]interface{}); ok {
+			value := param["value"]
+			if passwords[fmt.Sprint(param["name"])] {
+				value = maskedValue
+			}
+			params = append(params, fmt.Sprintf("%v=%v", param["name"], value))
+		}
+	}
+	return
+}
+
+// choosePipelineRun returns the namespace and PipelineRun from the arguments: [namespace] [pipelinerun].
+// The PipelineRun is selected from the ones of a Pipeline if it's not given, only the running ones are
+// listed if onlyRunning is true.
+func choosePipelineRun(client dynamic.Interface, args []string, pipeline string, onlyRunning bool) (
+	ns, pipelineRun string, err error) {
+	if ns, err = getNamespace(client, args); err != nil {
+		return
+	}
+	if len(args) > 1 {
+		pipelineRun = args[1]
+		return
+	}
+
+	if pipeline, err = choosePipeline(client, ns, pipeline); err != nil {
+		return
+	}
+
+	var items []unstructured.Unstructured
+	if items, err = getPipelineRunList(context.TODO(), client, ns, pipeline); err != nil {
+		return
+	}
+	var names []string
+	for i := range items {
+		if !onlyRunning || !isCompletedPhase(getPipelineRunPhase(&items[i])) {
+			names = append(names, items[i].GetName())
+		}
+	}
+	if len(names) == 0 {
+		err = fmt.Errorf("no PipelineRuns found of Pipeline %s/%s", ns, pipeline)
+		return
+	}
+	pipelineRun, err = option.ChooseObjectFromArray("PipelineRun", names)
+	return
+}
+
+// choosePipeline returns the given Pipeline, or the one which is selected from a namespace
+func choosePipeline(client dynamic.Interface, ns, pipeline string) (result string, err error) {
+	if result = pipeline; result != "" {
+		return
+	}
+
+	var pips []string
+	if _, pips, err = getPipelines(client, []string{ns}); err != nil {
+		return
+	}
+	if len(pips) == 0 {
+		err = fmt.Errorf("no Pipelines found in namespace '%s'", ns)
+		return
+	}
+	result, err = option.ChooseObjectFromArray("pipeline name", pips)
+	return
+}
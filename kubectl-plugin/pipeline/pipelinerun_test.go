@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newFakePipelineRun(name, pipeline string, creationTime time.Time) *unstructured.Unstructured {
+	run := &unstructured.Unstructured{}
+	run.SetAPIVersion("devops.kubesphere.io/v1alpha3")
+	run.SetKind("PipelineRun")
+	run.SetName(name)
+	run.SetNamespace("ns")
+	run.SetLabels(map[string]string{option.PipelinerunOwnerLabelKey: pipeline})
+	run.SetCreationTimestamp(metav1.NewTime(creationTime))
+	return run
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		types.GetPipelineRunSchema():   "PipelineRunList",
+		types.GetPipelineSchema():      "PipelineList",
+		types.GetDevOpsProjectSchema(): "DevOpsProjectList",
+		types.GetNamespaceSchema():     "NamespaceList",
+		types.GetSecretSchema():        "SecretList",
+		types.GetConfigMapSchema():     "ConfigMapList",
+		types.GetWorkspaceSchema():     "WorkspaceList",
+		types.GetWorkspaceTemplate():   "WorkspaceTemplateList",
+	}, objects...)
+}
+
+func TestGetLatestPipelineRun(t *testing.T) {
+	now := time.Now()
+	client := newFakeDynamicClient(
+		newFakePipelineRun("run-1", "pip", now.Add(-time.Hour)),
+		newFakePipelineRun("run-2", "pip", now),
+		newFakePipelineRun("run-3", "other", now.Add(time.Hour)))
+
+	run, err := getLatestPipelineRun(context.TODO(), client, "ns", "pip")
+	assert.Nil(t, err)
+	assert.Equal(t, "run-2", run.GetName())
+
+	_, err = getLatestPipelineRun(context.TODO(), client, "ns", "fake")
+	assert.NotNil(t, err)
+}
+
+func TestPipelineRunFields(t *testing.T) {
+	run := newFakePipelineRun("run-1", "pip", time.Now())
+	assert.Equal(t, "", getPipelineRunPhase(run))
+	assert.Equal(t, "pip", getPipelineRunPipeline(run))
+
+	_ = unstructured.SetNestedField(run.Object, option.PipelinerunPhaseFailed, "status", "phase")
+	_ = unstructured.SetNestedField(run.Object, "master", "spec", "scm", "refName")
+	_ = unstructured.SetNestedField(run.Object, "another", "spec", "pipelineRef", "name")
+	assert.True(t, isCompletedPhase(getPipelineRunPhase(run)))
+	assert.False(t, isCompletedPhase(option.PipelinerunPhaseRunning))
+	assert.Equal(t, "master", getPipelineRunBranch(run))
+	assert.Equal(t, "another", getPipelineRunPipeline(run))
+}
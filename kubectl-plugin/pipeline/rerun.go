@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newPipelineRerunCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineRerunOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:   "rerun",
+		Short: "Rerun a PipelineRun with the same Pipeline, parameters and SCM reference",
+		Long: `Rerun a PipelineRun with the same Pipeline, parameters and SCM reference
+The PipelineRun is selected from the ones of a Pipeline if it's not given.`,
+		Example: `ks pip rerun devops-ns my-pipeline-xxxxx
+ks pip rerun devops-ns --pipeline my-pipeline`,
+		Args:    cobra.MaximumNArgs(2),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.pipeline, "pipeline", "p", "",
+		"The Pipeline to select the PipelineRun from")
+	return
+}
+
+type pipelineRerunOption struct {
+	pipeline string
+
+	// inner fields
+	client      dynamic.Interface
+	namespace   string
+	pipelineRun string
+}
+
+func (o *pipelineRerunOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	o.namespace, o.pipelineRun, err = choosePipelineRun(o.client, args, o.pipeline, false)
+	return
+}
+
+func (o *pipelineRerunOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	ctx := context.TODO()
+	var source *unstructured.Unstructured
+	if source, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(o.namespace).Get(ctx, o.pipelineRun,
+		metav1.GetOptions{}); err != nil {
+		err = fmt.Errorf("cannot get PipelineRun %s/%s, error: %v", o.namespace, o.pipelineRun, err)
+		return
+	}
+
+	var pipelineRun *unstructured.Unstructured
+	if pipelineRun, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(o.namespace).Create(ctx,
+		newRerunPipelineRun(source), metav1.CreateOptions{}); err != nil {
+		err = fmt.Errorf("failed create PipelineRun, error: %v", err)
+		return
+	}
+	cmd.Printf("PipelineRun %s/%s created\n", o.namespace, pipelineRun.GetName())
+	return
+}
+
+// newRerunPipelineRun returns a new PipelineRun which has the same pipelineRef, parameters and SCM reference
+func newRerunPipelineRun(source *unstructured.Unstructured) (pipelineRun *unstructured.Unstructured) {
+	pipeline := getPipelineRunPipeline(source)
+	pipelineRun = &unstructured.Unstructured{Object: map[string]interface{}{}}
+	pipelineRun.SetAPIVersion(source.GetAPIVersion())
+	pipelineRun.SetKind(source.GetKind())
+	pipelineRun.SetNamespace(source.GetNamespace())
+	pipelineRun.SetGenerateName(pipeline)
+
+	spec := map[string]interface{}{
+		"pipelineRef": map[string]interface{}{"name": pipeline},
+	}
+	for _, key := range []string{"parameters", "scm"} {
+		if val, ok, _ := unstructured.NestedFieldCopy(source.Object, "spec", key); ok {
+			spec[key] = val
+		}
+	}
+	pipelineRun.Object["spec"] = spec
+	return
+}
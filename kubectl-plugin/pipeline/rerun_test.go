@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewRerunPipelineRun(t *testing.T) {
+	source := newFakePipelineRun("run-1", "pip", time.Now())
+	_ = unstructured.SetNestedField(source.Object, "pip", "spec", "pipelineRef", "name")
+	_ = unstructured.SetNestedSlice(source.Object, []interface{}{
+		map[string]interface{}{"name": "a", "value": "b"},
+	}, "spec", "parameters")
+	_ = unstructured.SetNestedField(source.Object, "main", "spec", "scm", "refName")
+	_ = unstructured.SetNestedField(source.Object, "branch", "spec", "scm", "refType")
+	_ = unstructured.SetNestedField(source.Object, "Succeeded", "status", "phase")
+
+	pipelineRun := newRerunPipelineRun(source)
+	assert.Equal(t, "pip", pipelineRun.GetGenerateName())
+	assert.Equal(t, "ns", pipelineRun.GetNamespace())
+	assert.Equal(t, map[string]interface{}{
+		"pipelineRef": map[string]interface{}{"name": "pip"},
+		"parameters":  []interface{}{map[string]interface{}{"name": "a", "value": "b"}},
+		"scm":         map[string]interface{}{"refName": "main", "refType": "branch"},
+	}, pipelineRun.Object["spec"])
+	assert.Nil(t, pipelineRun.Object["status"])
+
+	// rerun it via the command
+	client := newFakeDynamicClient(source)
+	buf := bytes.NewBuffer(nil)
+	cmd := newPipelineRerunCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "run-1"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "PipelineRun ns/")
+	list, err := client.Resource(types.GetPipelineRunSchema()).Namespace("ns").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, list.Items, 2)
+}
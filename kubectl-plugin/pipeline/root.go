@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,20 +12,18 @@ import (
 
 // NewPipelineCmd returns a command of pipeline
 func NewPipelineCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineListOption{
+		client: client,
+	}
 	cmd = &cobra.Command{
 		Use:     "pipeline",
 		Aliases: []string{"pip"},
 		Short:   "Manage the Pipeline of KubeSphere DevOps",
-		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			client := common.GetDynamicClient(cmd.Context())
-			var pips []string
-			if _, pips, err = getPipelines(client, args); err == nil {
-				for _, pip := range pips {
-					fmt.Println(pip)
-				}
-			}
-			return
-		},
+		Example: `ks pip devops-ns -o wide
+ks pip --all-namespaces -o name`,
+		// the namespace is the only argument, it must be declared to avoid being taken as a sub-command
+		Args: cobra.MaximumNArgs(1),
+		RunE: opt.runE,
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) (suggestion []string, directive cobra.ShellCompDirective) {
 			suggestion = getAllNamespace(client)
 			directive = cobra.ShellCompDirectiveNoFileComp
@@ -34,11 +31,23 @@ func NewPipelineCmd(client dynamic.Interface) (cmd *cobra.Command) {
 		},
 	}
 
+	opt.addFlags(cmd)
 	cmd.AddCommand(newDelPipelineCmd(client),
 		newPipelineEditCmd(client),
 		newPipelineViewCmd(client),
 		newPipelineCreateCmd(client),
 		newPipelineRunCmd(),
+		newPipelineLogsCmd(),
+		newPipelineAbortCmd(client),
+		newPipelineRerunCmd(client),
+		newPipelineHistoryCmd(client),
+		newPipelineStatsCmd(client),
+		newPipelineExportCmd(client),
+		newPipelineImportCmd(client),
+		newPipelineCloneCmd(client),
+		newPipelineApplyCmd(client),
+		newPipelineTriggerCmd(client),
+		newPipelineLintCmd(client),
 		newDashboardCmd(),
 		newGCCmd(client))
 	return
@@ -3,6 +3,8 @@ package pipeline
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
@@ -11,14 +13,21 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
+	"strings"
 	"text/template"
+	"time"
 )
 
 func newPipelineRunCmd() (cmd *cobra.Command) {
 	opt := &pipelineRunOpt{}
 	cmd = &cobra.Command{
-		Use:     "run",
-		Short:   "Start a Pipeline",
+		Use:   "run",
+		Short: "Start a Pipeline",
+		Long: `Start a Pipeline
+The branch, tag or pull request is required by a multi-branch Pipeline, it's chosen from the discovered branches if not given.`,
+		Example: `ks pip run -n devops-ns -p my-pipeline -P name=value -b
+ks pip run -n devops-ns -p my-multi-branch-pipeline --branch main -b
+ks pip run -n devops-ns -p my-multi-branch-pipeline --pr 1 --follow`,
 		PreRunE: opt.preRunE,
 		RunE:    opt.runE,
 	}
@@ -31,30 +40,67 @@ func newPipelineRunCmd() (cmd *cobra.Command) {
 	flags.StringVarP(&opt.project, "project", "", "",
 		"The project of target Pipeline")
 	flags.BoolVarP(&opt.batch, "batch", "b", false, "Run pipeline as batch mode")
-	flags.StringToStringVarP(&opt.parameters, "parameters", "P", map[string]string{}, "The parameters that you want to pass, example of single parameter: name=value")
+	flags.StringToStringVarP(&opt.parameters, "parameters", "P", map[string]string{}, "The parameters that you want to pass, example of single parameter: name=value. "+
+		"They are checked against the declared ones, except the parameters of a multi-branch Pipeline which are unknown")
+	flags.StringVarP(&opt.parametersFile, "parameters-file", "", "",
+		"A YAML file which contains the parameters, the ones from --parameters take precedence. "+
+			"They are checked against the declared ones, except the parameters of a multi-branch Pipeline which are unknown")
+	flags.BoolVarP(&opt.skipCheck, "skip-check", "", false,
+		"Skip checking the parameters against the declared ones of the Pipeline, the given ones are passed through")
+	flags.BoolVarP(&opt.wait, "wait", "w", false,
+		"Wait until the PipelineRun completes. The exit code is 0 if it succeeded, 1 if it failed, 2 if it was cancelled or timed out")
+	flags.DurationVarP(&opt.timeout, "timeout", "", time.Hour,
+		"The timeout of waiting for the PipelineRun, zero means no timeout")
+	flags.BoolVarP(&opt.follow, "follow", "f", false,
+		"Stream the log of the PipelineRun until it completes. This option implies --wait")
+	flags.DurationVarP(&opt.interval, "interval", "", 2*time.Second,
+		"The interval to check the status of the PipelineRun")
+	flags.StringVarP(&opt.branch, "branch", "", "",
+		"The branch to run of a multi-branch Pipeline")
+	flags.StringVarP(&opt.tag, "tag", "", "",
+		"The tag to run of a multi-branch Pipeline")
+	flags.StringVarP(&opt.pr, "pr", "", "",
+		"The pull request to run of a multi-branch Pipeline, such as: 1, PR-1, or MR-1 of GitLab")
+	opt.addDevOpsAPIFlags(flags)
 	return
 }
 
 type pipelineRunOpt struct {
-	pipeline   string
-	namespace  string
-	project    string
-	batch      bool
-	parameters map[string]string
+	pipeline       string
+	namespace      string
+	project        string
+	batch          bool
+	parameters     map[string]string
+	parametersFile string
+	wait           bool
+	timeout        time.Duration
+	follow         bool
+	interval       time.Duration
+	branch         string
+	tag            string
+	pr             string
+	skipCheck      bool
+	devopsAPIOption
 
 	// inner fields
 	client dynamic.Interface
+	// refName and refType are the reference of a multi-branch Pipeline, such as the branch name
+	refName string
+	refType string
 	option.PipelineCreateOption
 }
 
-func (o *pipelineRunOpt) triggerPipeline(ns, pipeline string, parameters map[string]string) (err error) {
-	pipelineRunYaml, err := parsePipelineRunTpl(map[string]interface{}{
+func (o *pipelineRunOpt) triggerPipeline(ns, pipeline string, parameters map[string]string) (
+	pipelineRun *unstructured.Unstructured, err error) {
+	var pipelineRunYaml string
+	if pipelineRunYaml, err = parsePipelineRunTpl(map[string]interface{}{
 		"name":       pipeline,
 		"namespace":  ns,
 		"parameters": parameters,
-	})
-	if err != nil {
-		return err
+		"refName":    o.refName,
+		"refType":    o.refType,
+	}); err != nil {
+		return
 	}
 
 	var pipelineRunObj *unstructured.Unstructured
@@ -63,7 +109,7 @@ func (o *pipelineRunOpt) triggerPipeline(ns, pipeline string, parameters map[str
 		return
 	}
 
-	if _, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(ns).Create(context.TODO(),
+	if pipelineRun, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(ns).Create(context.TODO(),
 		pipelineRunObj, metav1.CreateOptions{}); err != nil {
 		err = fmt.Errorf("failed create PipelineRun, error: %v", err)
 	}
@@ -91,14 +137,134 @@ func (o *pipelineRunOpt) preRunE(cmd *cobra.Command, args []string) (err error)
 	if err = o.wizard(cmd, args); err != nil {
 		return
 	}
+
+	if err = o.loadParametersFile(); err != nil {
+		return
+	}
+	if err = o.checkParameters(); err != nil {
+		return
+	}
+	if err = o.checkSCMRef(); err != nil {
+		return
+	}
+
+	if o.follow {
+		o.wait = true
+		err = o.initDevopsClient()
+	}
 	return
 }
 
-func (o *pipelineRunOpt) runE(_ *cobra.Command, _ []string) (err error) {
-	err = o.triggerPipeline(o.namespace, o.pipeline, o.parameters)
+func (o *pipelineRunOpt) runE(cmd *cobra.Command, _ []string) (err error) {
+	var pipelineRun *unstructured.Unstructured
+	if pipelineRun, err = o.triggerPipeline(o.namespace, o.pipeline, o.parameters); err != nil {
+		return
+	}
+	cmd.Printf("PipelineRun %s/%s created\n", o.namespace, pipelineRun.GetName())
+
+	if !o.wait {
+		return
+	}
+
+	var phase string
+	switch phase, err = o.waitPipelineRun(cmd, pipelineRun.GetName()); err {
+	case nil:
+		cmd.Printf("PipelineRun %s/%s %s\n", o.namespace, pipelineRun.GetName(), phase)
+		if code := getPipelineRunExitCode(phase); code != 0 {
+			// the phase is printed already, the error carries the exit code only
+			err = &common.ExitCodeError{Code: code}
+		}
+	case errWaitTimeout:
+		err = &common.ExitCodeError{Code: 2,
+			Message: fmt.Sprintf("timed out waiting for PipelineRun %s/%s", o.namespace, pipelineRun.GetName())}
+	}
 	return
 }
 
+var errWaitTimeout = errors.New("timed out waiting for the PipelineRun")
+
+// waitPipelineRun waits until the PipelineRun completes, returns errWaitTimeout if it's timeout
+func (o *pipelineRunOpt) waitPipelineRun(cmd *cobra.Command, name string) (phase string, err error) {
+	ctx := context.Background()
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	var logStart int64
+	stages := map[string]string{}
+	for {
+		var pipelineRun *unstructured.Unstructured
+		if pipelineRun, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(o.namespace).Get(ctx,
+			name, metav1.GetOptions{}); err != nil {
+			if ctx.Err() != nil {
+				err = errWaitTimeout
+			}
+			return
+		}
+		phase = getPipelineRunPhase(pipelineRun)
+
+		if runID := pipelineRun.GetAnnotations()[option.PipelinerunIdAnnotationKey]; o.follow && runID != "" {
+			var chunk *runLogChunk
+			if chunk, err = o.writeRunLog(ctx, cmd.OutOrStdout(), o.namespace, getPipelineRunPipeline(pipelineRun),
+				getPipelineRunBranch(pipelineRun), runID, logStart); err != nil {
+				err = fmt.Errorf("failed to get the log of PipelineRun %s/%s, error: %v", o.namespace, name, err)
+				return
+			}
+			logStart = chunk.next
+		} else if !o.follow {
+			printStageProgress(cmd, pipelineRun, stages)
+		}
+
+		if isCompletedPhase(phase) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			err = errWaitTimeout
+			return
+		case <-time.After(o.interval):
+		}
+	}
+}
+
+type pipelineRunStage struct {
+	DisplayName string `json:"displayName"`
+	State       string `json:"state"`
+	Result      string `json:"result"`
+}
+
+// printStageProgress prints the stages which status changed since last time
+func printStageProgress(cmd *cobra.Command, pipelineRun *unstructured.Unstructured, stages map[string]string) {
+	var nodes []pipelineRunStage
+	if err := json.Unmarshal([]byte(pipelineRun.GetAnnotations()[option.PipelinerunStagesStatusAnnotationKey]), &nodes); err != nil {
+		return
+	}
+
+	for _, node := range nodes {
+		status := strings.TrimSpace(fmt.Sprintf("%s %s", node.State, node.Result))
+		if status == "" || stages[node.DisplayName] == status {
+			continue
+		}
+		stages[node.DisplayName] = status
+		cmd.Printf("stage [%s] %s\n", node.DisplayName, status)
+	}
+}
+
+// getPipelineRunExitCode returns the exit code according to the phase of a PipelineRun
+func getPipelineRunExitCode(phase string) int {
+	switch phase {
+	case option.PipelinerunPhaseSucceeded:
+		return 0
+	case option.PipelinerunPhaseFailed:
+		return 1
+	default:
+		return 2
+	}
+}
+
 func (o *pipelineRunOpt) wizard(_ *cobra.Command, _ []string) (err error) {
 	if o.batch {
 		// without wizard in batch mode
@@ -197,4 +363,9 @@ spec:
       value: {{ $value | printf "%q" }}
 	{{- end }}
   {{- end }}
+  {{- if .refName }}
+  scm:
+    refName: {{ .refName | printf "%q" }}
+    refType: {{ .refType | printf "%q" }}
+  {{- end }}
 `
@@ -0,0 +1,212 @@
+package pipeline
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/jenkinsfile"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// loadParametersFile reads the parameters from a YAML file, the ones from the command line take precedence
+func (o *pipelineRunOpt) loadParametersFile() (err error) {
+	if o.parametersFile == "" {
+		return
+	}
+
+	var data []byte
+	if data, err = ioutil.ReadFile(o.parametersFile); err != nil {
+		err = fmt.Errorf("failed to read parameters file %s, error: %v", o.parametersFile, err)
+		return
+	}
+
+	params := map[string]interface{}{}
+	if err = yaml.Unmarshal(data, &params); err != nil {
+		err = fmt.Errorf("failed to parse parameters file %s, error: %v", o.parametersFile, err)
+		return
+	}
+
+	if o.parameters == nil {
+		o.parameters = map[string]string{}
+	}
+	for name, value := range params {
+		if _, ok := o.parameters[name]; !ok {
+			o.parameters[name] = fmt.Sprint(value)
+		}
+	}
+	return
+}
+
+// checkParameters validates the parameters against the declared ones of the Pipeline.
+// It fails if the declared ones cannot be parsed, the given parameters are passed through with --skip-check.
+func (o *pipelineRunOpt) checkParameters() (err error) {
+	if o.skipCheck {
+		return
+	}
+
+	var pip *unstructured.Unstructured
+	if pip, err = getPipeline(o.pipeline, o.namespace, o.client); err != nil {
+		err = fmt.Errorf("cannot get pipeline %s/%s, error: %v", o.namespace, o.pipeline, err)
+		return
+	}
+
+	var declared []jenkinsfile.Parameter
+	if declared, err = getPipelineParameters(pip); err != nil {
+		err = fmt.Errorf("failed to parse the parameters of pipeline %s/%s, use --skip-check to pass through the given ones, error: %v",
+			o.namespace, o.pipeline, err)
+		return
+	}
+
+	if !o.batch {
+		if err = askParameters(declared, o.parameters); err != nil {
+			return
+		}
+	}
+	o.parameters, err = resolveParameters(declared, o.parameters)
+	return
+}
+
+// getPipelineParameters returns the declared parameters of a Pipeline, it's nil if they are unknown.
+// The parameters in the Pipeline spec take precedence over the ones in the Jenkinsfile.
+func getPipelineParameters(pip *unstructured.Unstructured) (params []jenkinsfile.Parameter, err error) {
+	if pipelineType, _, _ := unstructured.NestedString(pip.Object, "spec", "type"); pipelineType != option.NoScmPipelineType {
+		return
+	}
+
+	specParams, _, _ := unstructured.NestedSlice(pip.Object, "spec", "pipeline", "parameters")
+	for _, item := range specParams {
+		specParam, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		param := jenkinsfile.Parameter{
+			Name:         fmt.Sprint(specParam["name"]),
+			Type:         toStringOrEmpty(specParam["type"]),
+			DefaultValue: toStringOrEmpty(specParam["default_value"]),
+			Description:  toStringOrEmpty(specParam["description"]),
+		}
+		if param.Type == jenkinsfile.ParameterTypeChoice {
+			// the choices are separated by newline in the default value
+			param.Choices = strings.Split(param.DefaultValue, "\n")
+			param.DefaultValue = param.Choices[0]
+		}
+		params = append(params, param)
+	}
+	if len(params) > 0 {
+		return
+	}
+
+	jenkinsfileText, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "jenkinsfile")
+	params, err = jenkinsfile.ParseParameters(jenkinsfileText)
+	return
+}
+
+func toStringOrEmpty(value interface{}) (result string) {
+	result, _ = value.(string)
+	return
+}
+
+// askParameters asks for the values of the parameters which are not given
+func askParameters(declared []jenkinsfile.Parameter, given map[string]string) (err error) {
+	for _, param := range declared {
+		if _, ok := given[param.Name]; ok {
+			continue
+		}
+
+		message := param.Name
+		if param.Description != "" {
+			message = fmt.Sprintf("%s (%s)", param.Name, param.Description)
+		}
+
+		var value string
+		switch param.Type {
+		case jenkinsfile.ParameterTypeBoolean:
+			defaultVal, _ := strconv.ParseBool(param.DefaultValue)
+			var confirmed bool
+			if err = survey.AskOne(&survey.Confirm{Message: message, Default: defaultVal}, &confirmed); err == nil {
+				value = strconv.FormatBool(confirmed)
+			}
+		case jenkinsfile.ParameterTypeChoice:
+			err = survey.AskOne(&survey.Select{Message: message, Options: param.Choices, Default: param.DefaultValue}, &value)
+		case jenkinsfile.ParameterTypePassword:
+			if err = survey.AskOne(&survey.Password{Message: message}, &value); err == nil && value == "" {
+				value = param.DefaultValue
+			}
+		case jenkinsfile.ParameterTypeFile:
+			// file parameters cannot be passed via PipelineRun
+			continue
+		default:
+			err = survey.AskOne(&survey.Input{Message: message, Default: param.DefaultValue}, &value)
+		}
+
+		if err != nil {
+			return
+		}
+		given[param.Name] = value
+	}
+	return
+}
+
+// resolveParameters validates the given parameters, and fills the default values of the missing ones
+func resolveParameters(declared []jenkinsfile.Parameter, given map[string]string) (result map[string]string, err error) {
+	if declared == nil {
+		result = given
+		return
+	}
+
+	declaredMap := make(map[string]jenkinsfile.Parameter, len(declared))
+	names := make([]string, 0, len(declared))
+	for _, param := range declared {
+		declaredMap[param.Name] = param
+		names = append(names, param.Name)
+	}
+	sort.Strings(names)
+
+	result = map[string]string{}
+	for name, value := range given {
+		param, ok := declaredMap[name]
+		if !ok {
+			err = fmt.Errorf("unknown parameter '%s', the declared parameters are: %v", name, names)
+			return
+		}
+
+		switch param.Type {
+		case jenkinsfile.ParameterTypeBoolean:
+			var val bool
+			if val, err = strconv.ParseBool(value); err != nil {
+				err = fmt.Errorf("the value of parameter '%s' should be true or false, got '%s'", name, value)
+				return
+			}
+			value = strconv.FormatBool(val)
+		case jenkinsfile.ParameterTypeChoice:
+			if !contains(param.Choices, value) {
+				err = fmt.Errorf("the value of parameter '%s' should be one of %v, got '%s'", name, param.Choices, value)
+				return
+			}
+		}
+		result[name] = value
+	}
+
+	for _, param := range declared {
+		if _, ok := result[param.Name]; !ok && param.Type != jenkinsfile.ParameterTypeFile {
+			result[param.Name] = param.DefaultValue
+		}
+	}
+	return
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
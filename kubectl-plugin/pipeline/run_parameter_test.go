@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/jenkinsfile"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/tpl"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetPipelineParameters(t *testing.T) {
+	pip := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"type": "pipeline",
+			"pipeline": map[string]interface{}{
+				"jenkinsfile": tpl.GetParameter(),
+			},
+		},
+	}}
+	params, err := getPipelineParameters(pip)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(params))
+
+	// the parameters of spec take precedence
+	_ = unstructured.SetNestedSlice(pip.Object, []interface{}{map[string]interface{}{
+		"name":          "version",
+		"type":          "choice",
+		"default_value": "v1\nv2",
+	}}, "spec", "pipeline", "parameters")
+	params, err = getPipelineParameters(pip)
+	assert.Nil(t, err)
+	assert.Equal(t, []jenkinsfile.Parameter{{
+		Name: "version", Type: jenkinsfile.ParameterTypeChoice, DefaultValue: "v1", Choices: []string{"v1", "v2"},
+	}}, params)
+
+	// the parameters of a multi-branch Pipeline are unknown
+	_ = unstructured.SetNestedField(pip.Object, "multi-branch-pipeline", "spec", "type")
+	params, err = getPipelineParameters(pip)
+	assert.Nil(t, err)
+	assert.Nil(t, params)
+}
+
+func TestResolveParameters(t *testing.T) {
+	declared, err := jenkinsfile.ParseParameters(tpl.GetParameter())
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		given   map[string]string
+		declare []jenkinsfile.Parameter
+		want    map[string]string
+		wantErr bool
+	}{{
+		name:  "unknown declared parameters",
+		given: map[string]string{"a": "b"},
+		want:  map[string]string{"a": "b"},
+	}, {
+		name:    "apply default values",
+		declare: declared,
+		given:   map[string]string{"debug": "True"},
+		want:    map[string]string{"name": "rick", "debug": "true", "kubernetesVersion": "v1.18.8"},
+	}, {
+		name:    "unknown parameter",
+		declare: declared,
+		given:   map[string]string{"nmae": "rick"},
+		wantErr: true,
+	}, {
+		name:    "invalid boolean",
+		declare: declared,
+		given:   map[string]string{"debug": "yes!"},
+		wantErr: true,
+	}, {
+		name:    "invalid choice",
+		declare: declared,
+		given:   map[string]string{"kubernetesVersion": "v1.20.0"},
+		wantErr: true,
+	}, {
+		name:    "no parameters declared",
+		declare: []jenkinsfile.Parameter{},
+		given:   map[string]string{"a": "b"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolveParameters(tt.declare, tt.given)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestCheckParameters(t *testing.T) {
+	scripted := newFakePipeline("ns", "scripted", `properties([parameters([string(name: 'a', defaultValue: 'b')])])
+node { echo params.a }`)
+	unknown := newFakePipeline("ns", "unknown",
+		`pipeline { parameters { string(name: 'a', defaultValue: 'a' + 'b') } }`)
+	opt := &pipelineRunOpt{
+		namespace: "ns",
+		batch:     true,
+		client:    newFakeDynamicClient(scripted, unknown),
+	}
+
+	// the parameters of a scripted Pipeline are passed through
+	opt.pipeline, opt.parameters = "scripted", map[string]string{"a": "c"}
+	assert.Nil(t, opt.checkParameters())
+	assert.Equal(t, map[string]string{"a": "c"}, opt.parameters)
+
+	// it fails if the Jenkinsfile cannot be parsed
+	opt.pipeline, opt.parameters = "unknown", map[string]string{"a": "c", "b": "d"}
+	err := opt.checkParameters()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "failed to parse the parameters of pipeline ns/unknown, use --skip-check")
+
+	// the parameters are passed through with --skip-check
+	opt.skipCheck = true
+	assert.Nil(t, opt.checkParameters())
+	assert.Equal(t, map[string]string{"a": "c", "b": "d"}, opt.parameters)
+}
+
+func TestLoadParametersFile(t *testing.T) {
+	file := path.Join(t.TempDir(), "params.yaml")
+	assert.Nil(t, os.WriteFile(file, []byte("name: rick\ndebug: true\n"), 0600))
+
+	opt := &pipelineRunOpt{
+		parametersFile: file,
+		parameters:     map[string]string{"name": "linuxsuren"},
+	}
+	assert.Nil(t, opt.loadParametersFile())
+	assert.Equal(t, map[string]string{"name": "linuxsuren", "debug": "true"}, opt.parameters)
+
+	opt.parametersFile = path.Join(t.TempDir(), "fake.yaml")
+	assert.NotNil(t, opt.loadParametersFile())
+}
@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The reference types of the PipelineRun which belongs to a multi-branch Pipeline
+const (
+	scmRefTypeBranch = "branch"
+	scmRefTypeTag    = "tag"
+	scmRefTypePR     = "pr"
+)
+
+// prBranchPattern matches the names of the pull requests which are discovered by Jenkins,
+// such as: PR-1, or MR-1 of GitLab
+var prBranchPattern = regexp.MustCompile(`^(PR|MR)-\d+$`)
+
+// checkSCMRef sets the reference of the PipelineRun according to --branch, --tag and --pr.
+// It asks for choosing one of the discovered branches of a multi-branch Pipeline if none of them is given.
+func (o *pipelineRunOpt) checkSCMRef() (err error) {
+	var count int
+	for _, val := range []string{o.branch, o.tag, o.pr} {
+		if val != "" {
+			count++
+		}
+	}
+	if count > 1 {
+		err = fmt.Errorf("only one of --branch, --tag and --pr can be given")
+		return
+	}
+
+	var pip *unstructured.Unstructured
+	if pip, err = getPipeline(o.pipeline, o.namespace, o.client); err != nil {
+		err = fmt.Errorf("cannot get pipeline %s/%s, error: %v", o.namespace, o.pipeline, err)
+		return
+	}
+	if pType, _, _ := unstructured.NestedString(pip.Object, "spec", "type"); pType != option.MultiBranchPipelineType {
+		if count > 0 {
+			err = fmt.Errorf("--branch, --tag and --pr are only supported by the multi-branch Pipeline")
+		}
+		return
+	}
+
+	switch {
+	case o.branch != "":
+		o.refName, o.refType = o.branch, scmRefTypeBranch
+	case o.tag != "":
+		o.refName, o.refType = o.tag, scmRefTypeTag
+	case o.pr != "":
+		o.refName, o.refType = getPRBranchName(pip, o.pr), scmRefTypePR
+	case o.batch:
+		err = fmt.Errorf("please provide one of --branch, --tag and --pr for the multi-branch Pipeline %s/%s",
+			o.namespace, o.pipeline)
+	default:
+		err = o.chooseBranch()
+	}
+	return
+}
+
+// getPRBranchName returns the name of a pull request which is discovered by Jenkins,
+// it's like PR-1, or MR-1 of GitLab. The name is taken as it is if it has the prefix already.
+func getPRBranchName(pip *unstructured.Unstructured, pr string) string {
+	if name := strings.ToUpper(pr); prBranchPattern.MatchString(name) {
+		return name
+	}
+
+	prefix := "PR-"
+	if sourceType, _, _ := unstructured.NestedString(pip.Object, "spec", "multi_branch_pipeline",
+		"source_type"); sourceType == option.SCMTypeGitLab {
+		prefix = "MR-"
+	}
+	return prefix + pr
+}
+
+// chooseBranch asks for choosing one of the branches which are discovered by the multi-branch Pipeline
+func (o *pipelineRunOpt) chooseBranch() (err error) {
+	if err = o.initDevopsClient(); err != nil {
+		return
+	}
+
+	var branches map[string]bool
+	if branches, err = o.getPipelineBranches(context.TODO(), o.namespace, o.pipeline); err != nil {
+		err = fmt.Errorf("failed to get the branches of pipeline %s/%s, error: %v", o.namespace, o.pipeline, err)
+		return
+	}
+	if len(branches) == 0 {
+		err = fmt.Errorf("no branches discovered by pipeline %s/%s, please scan the repository first",
+			o.namespace, o.pipeline)
+		return
+	}
+
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if o.refName, err = option.ChooseObjectFromArray("branch", names); err == nil {
+		o.refType = getSCMRefType(o.refName)
+	}
+	return
+}
+
+// getSCMRefType returns the reference type of a branch which is discovered by Jenkins
+func getSCMRefType(name string) string {
+	if prBranchPattern.MatchString(name) {
+		return scmRefTypePR
+	}
+	return scmRefTypeBranch
+}
@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCheckSCMRef(t *testing.T) {
+	multiBranch := newFakePipeline("ns", "multi", "")
+	_ = unstructured.SetNestedField(multiBranch.Object, option.MultiBranchPipelineType, "spec", "type")
+	client := newFakeDynamicClient(newFakePipeline("ns", "pip", "pipeline {}"), multiBranch)
+
+	opt := &pipelineRunOpt{namespace: "ns", pipeline: "multi", batch: true, pr: "1", client: client}
+	assert.Nil(t, opt.checkSCMRef())
+	assert.Equal(t, "PR-1", opt.refName)
+	assert.Equal(t, scmRefTypePR, opt.refType)
+
+	// the name is taken as it is if it has the prefix
+	opt = &pipelineRunOpt{namespace: "ns", pipeline: "multi", batch: true, pr: "pr-2", client: client}
+	assert.Nil(t, opt.checkSCMRef())
+	assert.Equal(t, "PR-2", opt.refName)
+
+	// GitLab names the merge requests like MR-1
+	gitlab := newFakePipeline("ns", "gitlab", "")
+	_ = unstructured.SetNestedField(gitlab.Object, option.MultiBranchPipelineType, "spec", "type")
+	_ = unstructured.SetNestedField(gitlab.Object, option.SCMTypeGitLab, "spec", "multi_branch_pipeline", "source_type")
+	opt = &pipelineRunOpt{namespace: "ns", pipeline: "gitlab", batch: true, pr: "3",
+		client: newFakeDynamicClient(gitlab)}
+	assert.Nil(t, opt.checkSCMRef())
+	assert.Equal(t, "MR-3", opt.refName)
+	assert.Equal(t, scmRefTypePR, opt.refType)
+
+	opt = &pipelineRunOpt{namespace: "ns", pipeline: "multi", batch: true, tag: "v1.0.0", client: client}
+	assert.Nil(t, opt.checkSCMRef())
+	assert.Equal(t, "v1.0.0", opt.refName)
+	assert.Equal(t, scmRefTypeTag, opt.refType)
+
+	opt = &pipelineRunOpt{namespace: "ns", pipeline: "multi", batch: true, branch: "main", client: client}
+	assert.Nil(t, opt.checkSCMRef())
+	assert.Equal(t, "main", opt.refName)
+	assert.Equal(t, scmRefTypeBranch, opt.refType)
+
+	// the Pipeline without SCM has no reference
+	opt = &pipelineRunOpt{namespace: "ns", pipeline: "pip", batch: true, client: client}
+	assert.Nil(t, opt.checkSCMRef())
+	assert.Empty(t, opt.refName)
+
+	for _, invalid := range []*pipelineRunOpt{
+		{namespace: "ns", pipeline: "pip", branch: "main"},
+		{namespace: "ns", pipeline: "multi", branch: "main", tag: "v1.0.0"},
+		{namespace: "ns", pipeline: "multi", batch: true},
+		{namespace: "ns", pipeline: "fake", batch: true},
+	} {
+		invalid.client = client
+		assert.NotNil(t, invalid.checkSCMRef())
+	}
+
+	assert.Equal(t, scmRefTypePR, getSCMRefType("PR-12"))
+	assert.Equal(t, scmRefTypePR, getSCMRefType("MR-12"))
+	assert.Equal(t, scmRefTypeBranch, getSCMRefType("PR-fix"))
+	assert.Equal(t, scmRefTypeBranch, getSCMRefType("main"))
+}
@@ -3,11 +3,17 @@ package pipeline
 import (
 	"bytes"
 	"fmt"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"html/template"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 	"sigs.k8s.io/yaml"
 	"testing"
+	"time"
 )
 
 func TestPipelineRunTplParse(t *testing.T) {
@@ -116,6 +122,20 @@ func Test_parsePipelineRunTpl(t *testing.T) {
 			assert.Equal(t, 1, len(getNestSlice(obj.Object, "spec", "parameters")))
 			assert.Equal(t, map[string]interface{}{"name": "a", "value": "b"}, getNestSlice(obj.Object, "spec", "parameters")[0])
 		},
+	}, {
+		name: "With the branch",
+		args: args{
+			data: map[string]interface{}{
+				"name":      "fake_name",
+				"namespace": "fake_namespace",
+				"refName":   "main",
+				"refType":   "branch",
+			},
+		},
+		pipelineRunAssert: func(obj *unstructured.Unstructured) {
+			assert.Equal(t, "main", getNestedString(obj.Object, "spec", "scm", "refName"))
+			assert.Equal(t, "branch", getNestedString(obj.Object, "spec", "scm", "refType"))
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -134,3 +154,99 @@ func Test_parsePipelineRunTpl(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPipelineRunExitCode(t *testing.T) {
+	assert.Equal(t, 0, getPipelineRunExitCode(option.PipelinerunPhaseSucceeded))
+	assert.Equal(t, 1, getPipelineRunExitCode(option.PipelinerunPhaseFailed))
+	assert.Equal(t, 2, getPipelineRunExitCode(option.PipelinerunPhaseCancelled))
+	assert.Equal(t, 2, getPipelineRunExitCode(""))
+}
+
+func TestWaitPipelineRun(t *testing.T) {
+	run := newFakePipelineRun("run-1", "pip", time.Now())
+	run.SetAnnotations(map[string]string{
+		option.PipelinerunStagesStatusAnnotationKey: `[{"displayName":"build","state":"FINISHED","result":"SUCCESS"},{"displayName":"test","state":"RUNNING"}]`,
+	})
+	_ = unstructured.SetNestedField(run.Object, option.PipelinerunPhaseRunning, "status", "phase")
+
+	opt := &pipelineRunOpt{
+		namespace: "ns",
+		timeout:   10 * time.Millisecond,
+		interval:  time.Millisecond,
+		client:    newFakeDynamicClient(run),
+	}
+	buf := bytes.NewBuffer(nil)
+	cmd := &cobra.Command{}
+	cmd.SetOut(buf)
+
+	phase, err := opt.waitPipelineRun(cmd, "run-1")
+	assert.Equal(t, errWaitTimeout, err)
+	assert.Equal(t, option.PipelinerunPhaseRunning, phase)
+	assert.Equal(t, "stage [build] FINISHED SUCCESS\nstage [test] RUNNING\n", buf.String())
+
+	_ = unstructured.SetNestedField(run.Object, option.PipelinerunPhaseSucceeded, "status", "phase")
+	opt.client = newFakeDynamicClient(run)
+	phase, err = opt.waitPipelineRun(cmd, "run-1")
+	assert.Nil(t, err)
+	assert.Equal(t, option.PipelinerunPhaseSucceeded, phase)
+}
+
+func TestPipelineRunExitCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		phase   string
+		timeout time.Duration
+		output  string
+		wantErr *common.ExitCodeError
+	}{{
+		name:   "succeeded",
+		phase:  option.PipelinerunPhaseSucceeded,
+		output: "PipelineRun ns/pip-1 Succeeded\n",
+	}, {
+		name:    "failed",
+		phase:   option.PipelinerunPhaseFailed,
+		output:  "PipelineRun ns/pip-1 Failed\n",
+		wantErr: &common.ExitCodeError{Code: 1},
+	}, {
+		name:    "cancelled",
+		phase:   option.PipelinerunPhaseCancelled,
+		output:  "PipelineRun ns/pip-1 Cancelled\n",
+		wantErr: &common.ExitCodeError{Code: 2},
+	}, {
+		name:    "timeout",
+		phase:   option.PipelinerunPhaseRunning,
+		timeout: 10 * time.Millisecond,
+		wantErr: &common.ExitCodeError{Code: 2, Message: "timed out waiting for PipelineRun ns/pip-1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeDynamicClient()
+			client.PrependReactor("create", "pipelineruns", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+				obj.SetName(obj.GetGenerateName() + "-1")
+				_ = unstructured.SetNestedField(obj.Object, tt.phase, "status", "phase")
+				return false, nil, nil
+			})
+
+			opt := &pipelineRunOpt{
+				namespace: "ns",
+				pipeline:  "pip",
+				wait:      true,
+				timeout:   tt.timeout,
+				interval:  time.Millisecond,
+			}
+			opt.client = client
+			buf := bytes.NewBuffer(nil)
+			cmd := &cobra.Command{}
+			cmd.SetOut(buf)
+
+			err := opt.runE(cmd, nil)
+			if tt.wantErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, tt.wantErr, err)
+			}
+			assert.Equal(t, "PipelineRun ns/pip-1 created\n"+tt.output, buf.String())
+		})
+	}
+}
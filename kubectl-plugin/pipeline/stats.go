@@ -0,0 +1,306 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+)
+
+const outputFormatCSV = "csv"
+
+func newPipelineStatsCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineStatsOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Output the statistics of Pipelines",
+		Long: `Output the statistics of Pipelines which come from the completed PipelineRuns
+The statistics include success rate, failure rate, p50 and p95 duration, mean time to recovery and flaky score.
+The flaky score is the ratio of outcome changes between the consecutive runs on the same commit.`,
+		Example: `ks pip stats devops-ns another-devops-ns --since 168h
+ks pip stats -o csv`,
+		PreRunE: opt.preRunE,
+		RunE:    opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.DurationVarP(&opt.since, "since", "", 7*24*time.Hour,
+		"Only count the PipelineRuns which were created in this duration, zero means no limit")
+	flags.StringVarP(&opt.output, "output", "o", "",
+		"The output format, supported formats: json, csv")
+
+	_ = cmd.RegisterFlagCompletionFunc("output", common.ArrayCompletion(outputFormatJSON, outputFormatCSV))
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return getAllNamespace(client), cobra.ShellCompDirectiveNoFileComp
+	}
+	return
+}
+
+type pipelineStatsOption struct {
+	since  time.Duration
+	output string
+
+	// inner fields
+	client     dynamic.Interface
+	namespaces []string
+}
+
+// pipelineStats is the statistics of a Pipeline, all durations are in seconds
+type pipelineStats struct {
+	Namespace   string  `json:"namespace"`
+	Pipeline    string  `json:"pipeline"`
+	Runs        int     `json:"runs"`
+	Succeeded   int     `json:"succeeded"`
+	Failed      int     `json:"failed"`
+	Cancelled   int     `json:"cancelled"`
+	SuccessRate float64 `json:"successRate"`
+	FailureRate float64 `json:"failureRate"`
+	P50Duration float64 `json:"p50Duration"`
+	P95Duration float64 `json:"p95Duration"`
+	MTTR        float64 `json:"meanTimeToRecovery"`
+	FlakyScore  float64 `json:"flakyScore"`
+}
+
+func (o *pipelineStatsOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	if o.namespaces = args; len(o.namespaces) == 0 {
+		if o.namespaces = getAllNamespace(o.client); len(o.namespaces) == 0 {
+			err = fmt.Errorf("no pipeline namespace found in this cluster")
+		}
+	}
+	return
+}
+
+func (o *pipelineStatsOption) runE(cmd *cobra.Command, _ []string) (err error) {
+	ctx := context.TODO()
+	var result []pipelineStats
+	for _, ns := range o.namespaces {
+		var list *unstructured.UnstructuredList
+		if list, err = o.client.Resource(types.GetPipelineRunSchema()).Namespace(ns).List(ctx, metav1.ListOptions{}); err != nil {
+			err = fmt.Errorf("failed to get PipelineRun list in '%s', error: %v", ns, err)
+			return
+		}
+
+		pipelineRuns := map[string][]unstructured.Unstructured{}
+		for _, item := range list.Items {
+			if o.since > 0 && item.GetCreationTimestamp().Time.Before(time.Now().Add(-o.since)) {
+				continue
+			}
+			pipeline := getPipelineRunPipeline(&item)
+			pipelineRuns[pipeline] = append(pipelineRuns[pipeline], item)
+		}
+
+		pipelines := make([]string, 0, len(pipelineRuns))
+		for pipeline := range pipelineRuns {
+			pipelines = append(pipelines, pipeline)
+		}
+		sort.Strings(pipelines)
+		for _, pipeline := range pipelines {
+			result = append(result, computePipelineStats(ns, pipeline, pipelineRuns[pipeline]))
+		}
+	}
+
+	switch o.output {
+	case outputFormatJSON:
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+	case outputFormatCSV:
+		err = printStatsCSV(cmd.OutOrStdout(), result)
+	case "":
+		err = printStatsTable(cmd.OutOrStdout(), result)
+	default:
+		err = fmt.Errorf("not supported output format: %s", o.output)
+	}
+	return
+}
+
+// computePipelineStats computes the statistics from the completed PipelineRuns of a Pipeline
+func computePipelineStats(ns, pipeline string, items []unstructured.Unstructured) (stats pipelineStats) {
+	stats = pipelineStats{Namespace: ns, Pipeline: pipeline}
+
+	var runs []*statsRun
+	for i := range items {
+		item := &items[i]
+		phase := getPipelineRunPhase(item)
+		completionTime, err := getCompletionTimeFromObject(item.Object)
+		if !isCompletedPhase(phase) || err != nil {
+			continue
+		}
+
+		runs = append(runs, &statsRun{
+			phase:          phase,
+			branch:         getPipelineRunBranch(item),
+			commit:         getJenkinsRunStatus(item).CommitID,
+			completionTime: completionTime,
+			duration:       completionTime.Sub(getPipelineRunStartTime(item)),
+		})
+		switch phase {
+		case option.PipelinerunPhaseSucceeded:
+			stats.Succeeded++
+		case option.PipelinerunPhaseFailed:
+			stats.Failed++
+		case option.PipelinerunPhaseCancelled:
+			stats.Cancelled++
+		}
+	}
+
+	if stats.Runs = len(runs); stats.Runs == 0 {
+		return
+	}
+	stats.SuccessRate = round(float64(stats.Succeeded) / float64(stats.Runs))
+	stats.FailureRate = round(float64(stats.Failed) / float64(stats.Runs))
+
+	durations := make([]float64, len(runs))
+	for i, run := range runs {
+		durations[i] = run.duration.Seconds()
+	}
+	sort.Float64s(durations)
+	stats.P50Duration = percentile(durations, 50)
+	stats.P95Duration = percentile(durations, 95)
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].completionTime.Before(runs[j].completionTime)
+	})
+	stats.MTTR = round(meanTimeToRecovery(runs).Seconds())
+	stats.FlakyScore = round(flakyScore(runs))
+	return
+}
+
+type statsRun struct {
+	phase          string
+	branch         string
+	commit         string
+	completionTime time.Time
+	duration       time.Duration
+}
+
+// meanTimeToRecovery returns the mean duration from the first failure to the next success on the same branch,
+// the runs need to be sorted by the completion time
+func meanTimeToRecovery(runs []*statsRun) time.Duration {
+	var total time.Duration
+	var count int
+	failedSince := map[string]time.Time{}
+	for _, run := range runs {
+		switch run.phase {
+		case option.PipelinerunPhaseFailed:
+			if _, ok := failedSince[run.branch]; !ok {
+				failedSince[run.branch] = run.completionTime
+			}
+		case option.PipelinerunPhaseSucceeded:
+			if since, ok := failedSince[run.branch]; ok {
+				total += run.completionTime.Sub(since)
+				count++
+				delete(failedSince, run.branch)
+			}
+		}
+	}
+
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
+
+// flakyScore returns the ratio of outcome changes between the consecutive runs on the same commit,
+// the runs need to be sorted by the completion time
+func flakyScore(runs []*statsRun) float64 {
+	var pairs, changes int
+	lastPhase := map[string]string{}
+	for _, run := range runs {
+		if run.commit == "" || run.phase == option.PipelinerunPhaseCancelled {
+			continue
+		}
+
+		if last, ok := lastPhase[run.commit]; ok {
+			pairs++
+			if last != run.phase {
+				changes++
+			}
+		}
+		lastPhase[run.commit] = run.phase
+	}
+
+	if pairs == 0 {
+		return 0
+	}
+	return float64(changes) / float64(pairs)
+}
+
+// percentile returns the nearest-rank percentile of the sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return round(sorted[rank-1])
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func getStatsHeaders() []string {
+	return []string{"NAMESPACE", "PIPELINE", "RUNS", "SUCCESS", "FAILURE", "P50", "P95", "MTTR", "FLAKY"}
+}
+
+func printStatsTable(w io.Writer, result []pipelineStats) error {
+	rows := make([][]string, len(result))
+	for i, stats := range result {
+		rows[i] = []string{stats.Namespace, stats.Pipeline, strconv.Itoa(stats.Runs),
+			fmt.Sprintf("%.0f%%", stats.SuccessRate*100), fmt.Sprintf("%.0f%%", stats.FailureRate*100),
+			humanSeconds(stats.P50Duration), humanSeconds(stats.P95Duration), humanSeconds(stats.MTTR),
+			strconv.FormatFloat(stats.FlakyScore, 'f', 2, 64)}
+	}
+	return common.PrintTable(w, getStatsHeaders(), rows)
+}
+
+func printStatsCSV(w io.Writer, result []pipelineStats) (err error) {
+	writer := csv.NewWriter(w)
+	if err = writer.Write(getStatsHeaders()); err != nil {
+		return
+	}
+	for _, stats := range result {
+		if err = writer.Write([]string{stats.Namespace, stats.Pipeline, strconv.Itoa(stats.Runs),
+			formatFloat(stats.SuccessRate), formatFloat(stats.FailureRate), formatFloat(stats.P50Duration),
+			formatFloat(stats.P95Duration), formatFloat(stats.MTTR), formatFloat(stats.FlakyScore)}); err != nil {
+			return
+		}
+	}
+	writer.Flush()
+	err = writer.Error()
+	return
+}
+
+func humanSeconds(seconds float64) string {
+	if seconds == 0 {
+		return "-"
+	}
+	return duration.HumanDuration(time.Duration(seconds * float64(time.Second)))
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
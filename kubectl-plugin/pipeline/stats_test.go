@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newFakeCompletedRun(name, phase, commit string, start time.Time, duration time.Duration) *unstructured.Unstructured {
+	run := newFakePipelineRun(name, "pip", start)
+	_ = unstructured.SetNestedField(run.Object, phase, "status", "phase")
+	_ = unstructured.SetNestedField(run.Object, start.Format(time.RFC3339), "status", "startTime")
+	_ = unstructured.SetNestedField(run.Object, start.Add(duration).Format(time.RFC3339), "status", "completionTime")
+	run.SetAnnotations(map[string]string{
+		option.PipelinerunStatusAnnotationKey: fmt.Sprintf(`{"commitId":"%s"}`, commit),
+	})
+	return run
+}
+
+func TestComputePipelineStats(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	items := []unstructured.Unstructured{
+		*newFakeCompletedRun("run-1", option.PipelinerunPhaseSucceeded, "a", now.Add(-5*time.Hour), time.Minute),
+		*newFakeCompletedRun("run-2", option.PipelinerunPhaseFailed, "b", now.Add(-4*time.Hour), 2*time.Minute),
+		*newFakeCompletedRun("run-3", option.PipelinerunPhaseSucceeded, "b", now.Add(-3*time.Hour), 3*time.Minute),
+		*newFakeCompletedRun("run-4", option.PipelinerunPhaseFailed, "b", now.Add(-2*time.Hour), 4*time.Minute),
+		*newFakeCompletedRun("run-5", option.PipelinerunPhaseCancelled, "c", now.Add(-time.Hour), 5*time.Minute),
+		*newFakePipelineRun("run-6", "pip", now),
+	}
+
+	stats := computePipelineStats("ns", "pip", items)
+	assert.Equal(t, pipelineStats{
+		Namespace:   "ns",
+		Pipeline:    "pip",
+		Runs:        5,
+		Succeeded:   2,
+		Failed:      2,
+		Cancelled:   1,
+		SuccessRate: 0.4,
+		FailureRate: 0.4,
+		P50Duration: 180,
+		P95Duration: 300,
+		// from the completion of run-2 to the completion of run-3
+		MTTR: 3660,
+		// b: failed -> succeeded -> failed
+		FlakyScore: 1,
+	}, stats)
+
+	assert.Equal(t, pipelineStats{Namespace: "ns", Pipeline: "pip"}, computePipelineStats("ns", "pip", nil))
+}
+
+func TestPipelineStatsOutput(t *testing.T) {
+	now := time.Now()
+	opt := &pipelineStatsOption{
+		client: newFakeDynamicClient(
+			newFakeCompletedRun("run-1", option.PipelinerunPhaseSucceeded, "a", now.Add(-time.Hour), time.Minute),
+			newFakeCompletedRun("run-2", option.PipelinerunPhaseFailed, "a", now.Add(-30*24*time.Hour), time.Minute)),
+		namespaces: []string{"ns"},
+		since:      24 * time.Hour,
+		output:     outputFormatCSV,
+	}
+	cmd := &cobra.Command{}
+	buf := bytes.NewBuffer(nil)
+	cmd.SetOut(buf)
+
+	assert.Nil(t, opt.runE(cmd, nil))
+	assert.Equal(t, `NAMESPACE,PIPELINE,RUNS,SUCCESS,FAILURE,P50,P95,MTTR,FLAKY
+ns,pip,1,1,0,60,60,0,0
+`, buf.String())
+
+	buf.Reset()
+	opt.output = ""
+	assert.Nil(t, opt.runE(cmd, nil))
+	assert.Equal(t, `NAMESPACE   PIPELINE   RUNS   SUCCESS   FAILURE   P50   P95   MTTR   FLAKY
+ns          pip        1      100%      0%        60s   60s   -      0.00
+`, buf.String())
+
+	opt.output = "fake"
+	assert.NotNil(t, opt.runE(cmd, nil))
+}
@@ -0,0 +1,106 @@
+package tpl
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"sigs.k8s.io/yaml"
+)
+
+// Template is a user-defined Pipeline template
+type Template struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	SCMType     string             `json:"scmType,omitempty"`
+	Variables   []TemplateVariable `json:"variables,omitempty"`
+	Jenkinsfile string             `json:"jenkinsfile,omitempty"`
+}
+
+// TemplateVariable is a variable which is declared by a template
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// GetTemplateDir returns the default directory of the user-defined templates
+func GetTemplateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ks", "pipeline-templates")
+}
+
+// ParseTemplate parses a template from YAML
+func ParseTemplate(data []byte) (tpl *Template, err error) {
+	tpl = &Template{}
+	if err = yaml.Unmarshal(data, tpl); err == nil && tpl.Name == "" {
+		err = fmt.Errorf("the name of template is required")
+	}
+	return
+}
+
+// LoadTemplatesFromDir loads all the YAML templates from a directory, it's fine if the directory does not exist
+func LoadTemplatesFromDir(dir string) (templates []Template, err error) {
+	var files []os.FileInfo
+	if files, err = ioutil.ReadDir(dir); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		var data []byte
+		if data, err = ioutil.ReadFile(filepath.Join(dir, file.Name())); err != nil {
+			return
+		}
+
+		var tpl *Template
+		if tpl, err = ParseTemplate(data); err != nil {
+			err = fmt.Errorf("failed to parse template %s, error: %v", file.Name(), err)
+			return
+		}
+		templates = append(templates, *tpl)
+	}
+	return
+}
+
+// Render renders the Jenkinsfile with the given values, the default value will be used if a variable is missing
+func (t *Template) Render(values map[string]string) (result string, err error) {
+	data := map[string]string{}
+	for _, variable := range t.Variables {
+		data[variable.Name] = variable.Default
+	}
+	for key, val := range values {
+		data[key] = val
+	}
+
+	var tpl *template.Template
+	if tpl, err = template.New(t.Name).Funcs(sprig.TxtFuncMap()).Option("missingkey=error").
+		Parse(t.Jenkinsfile); err != nil {
+		err = fmt.Errorf("failed to parse template %s, error: %v", t.Name, err)
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = tpl.Execute(buf, data); err != nil {
+		err = fmt.Errorf("failed to render template %s, error: %v", t.Name, err)
+		return
+	}
+	result = strings.TrimSpace(buf.String())
+	return
+}
@@ -0,0 +1,77 @@
+package tpl_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/tpl"
+	"github.com/stretchr/testify/assert"
+)
+
+var customTemplate = `
+name: maven
+description: Build a maven project
+type: pipeline
+variables:
+- name: image
+  default: maven:3-jdk-8
+- name: goal
+jenkinsfile: |
+  pipeline {
+    agent { docker { image '{{.image}}' } }
+    stages {
+      stage('build') { steps { sh 'mvn {{.goal | default "package"}}' } }
+    }
+  }
+`
+
+func TestLoadTemplatesFromDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "maven.yaml"), []byte(customTemplate), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0644))
+
+	templates, err := tpl.LoadTemplatesFromDir(dir)
+	assert.Nil(t, err)
+	if assert.Len(t, templates, 1) {
+		assert.Equal(t, "maven", templates[0].Name)
+		assert.Equal(t, "pipeline", templates[0].Type)
+		assert.Len(t, templates[0].Variables, 2)
+	}
+
+	// it's fine if the directory does not exist
+	templates, err = tpl.LoadTemplatesFromDir(filepath.Join(dir, "fake"))
+	assert.Nil(t, err)
+	assert.Empty(t, templates)
+
+	// the name is required
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "invalid.yml"), []byte("type: pipeline"), 0644))
+	_, err = tpl.LoadTemplatesFromDir(dir)
+	assert.NotNil(t, err)
+}
+
+func TestTemplateRender(t *testing.T) {
+	template, err := tpl.ParseTemplate([]byte(customTemplate))
+	assert.Nil(t, err)
+
+	result, err := template.Render(nil)
+	assert.Nil(t, err)
+	assert.Contains(t, result, "image 'maven:3-jdk-8'")
+	assert.Contains(t, result, "sh 'mvn package'")
+
+	result, err = template.Render(map[string]string{"image": "maven:3", "goal": "install"})
+	assert.Nil(t, err)
+	assert.Contains(t, result, "image 'maven:3'")
+	assert.Contains(t, result, "sh 'mvn install'")
+
+	// undeclared variable
+	template.Jenkinsfile = "{{.fake}}"
+	_, err = template.Render(nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,240 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func newPipelineTriggerCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	cmd = &cobra.Command{
+		Use:   "trigger",
+		Short: "Manage the timer and remote triggers of a Pipeline",
+		Long: `Manage the timer and remote triggers of a Pipeline
+The cron and remote token triggers belong to the Pipeline with a Jenkinsfile inside,
+the scan interval belongs to the multi-branch Pipeline.`,
+	}
+
+	cmd.AddCommand(newPipelineTriggerSetCmd(client),
+		newPipelineTriggerGetCmd(client),
+		newPipelineTriggerClearCmd(client))
+	return
+}
+
+func newPipelineTriggerSetCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineTriggerOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:   "set",
+		Short: "Set the triggers of a Pipeline",
+		Long: `Set the triggers of a Pipeline
+The cron follows the syntax of Jenkins, H means a hashed value which spreads the load, such as: H 2 * * *`,
+		Example: `ks pip trigger set devops-ns my-pipeline --cron 'H 2 * * *'
+ks pip trigger set devops-ns my-pipeline --remote-token my-token
+ks pip trigger set devops-ns my-multi-branch-pipeline --scan-interval 1h`,
+		Args:    cobra.MaximumNArgs(2),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runSetE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.cron, "cron", "", "",
+		"The cron of the timer trigger, such as: H 2 * * *")
+	flags.StringVarP(&opt.remoteToken, "remote-token", "", "",
+		"The token to trigger the Pipeline remotely, such as via the SCM webhook")
+	flags.DurationVarP(&opt.scanInterval, "scan-interval", "", 0,
+		"The interval of scanning the repository of a multi-branch Pipeline, such as: 1h")
+	return
+}
+
+func newPipelineTriggerGetCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineTriggerOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:   "get",
+		Short: "Print the triggers of a Pipeline",
+		Long: `Print the triggers of a Pipeline
+The remote token is masked unless --show-token is given.`,
+		Example: `ks pip trigger get devops-ns my-pipeline
+ks pip trigger get devops-ns my-pipeline --show-token`,
+		Args:    cobra.MaximumNArgs(2),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runGetE,
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opt.showToken, "show-token", "", false,
+		"Print the remote token in clear text")
+	return
+}
+
+func newPipelineTriggerClearCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineTriggerOption{
+		client: client,
+	}
+	cmd = &cobra.Command{
+		Use:     "clear",
+		Short:   "Remove all the triggers of a Pipeline",
+		Example: `ks pip trigger clear devops-ns my-pipeline`,
+		Args:    cobra.MaximumNArgs(2),
+		PreRunE: opt.preRunE,
+		RunE:    opt.runClearE,
+	}
+	return
+}
+
+type pipelineTriggerOption struct {
+	cron         string
+	remoteToken  string
+	scanInterval time.Duration
+	showToken    bool
+
+	// inner fields
+	client    dynamic.Interface
+	namespace string
+	pipeline  string
+}
+
+func (o *pipelineTriggerOption) preRunE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+
+	if o.namespace, err = getNamespace(o.client, args); err != nil {
+		return
+	}
+	if len(args) > 1 {
+		o.pipeline = args[1]
+	}
+	o.pipeline, err = choosePipeline(o.client, o.namespace, o.pipeline)
+	return
+}
+
+func (o *pipelineTriggerOption) runSetE(cmd *cobra.Command, _ []string) (err error) {
+	flags := cmd.Flags()
+	if !flags.Changed("cron") && !flags.Changed("remote-token") && !flags.Changed("scan-interval") {
+		err = fmt.Errorf("please provide at least one of --cron, --remote-token and --scan-interval")
+		return
+	}
+
+	var pip *unstructured.Unstructured
+	if pip, err = o.getPipeline(); err != nil {
+		return
+	}
+
+	pType, _, _ := unstructured.NestedString(pip.Object, "spec", "type")
+	switch pType {
+	case option.NoScmPipelineType:
+		if flags.Changed("scan-interval") {
+			err = fmt.Errorf("--scan-interval is only supported by the multi-branch Pipeline")
+			return
+		}
+		if flags.Changed("cron") {
+			if err = validateJenkinsCron(o.cron); err != nil {
+				err = fmt.Errorf("invalid cron, error: %v", err)
+				return
+			}
+			if err = unstructured.SetNestedField(pip.Object, o.cron, "spec", "pipeline", "timer_trigger", "cron"); err != nil {
+				return
+			}
+		}
+		if flags.Changed("remote-token") {
+			if err = unstructured.SetNestedField(pip.Object, o.remoteToken,
+				"spec", "pipeline", "remote_trigger", "token"); err != nil {
+				return
+			}
+		}
+	case option.MultiBranchPipelineType:
+		if flags.Changed("cron") || flags.Changed("remote-token") {
+			err = fmt.Errorf("--cron and --remote-token are not supported by the multi-branch Pipeline, please use --scan-interval")
+			return
+		}
+		if o.scanInterval < time.Minute {
+			err = fmt.Errorf("the scan interval should not be less than 1m, got: %v", o.scanInterval)
+			return
+		}
+		// Jenkins takes the interval in milliseconds
+		if err = unstructured.SetNestedField(pip.Object, strconv.FormatInt(o.scanInterval.Milliseconds(), 10),
+			"spec", "multi_branch_pipeline", "timer_trigger", "interval"); err != nil {
+			return
+		}
+	default:
+		err = fmt.Errorf("not supported Pipeline type: '%s'", pType)
+		return
+	}
+
+	if err = o.updatePipeline(pip); err == nil {
+		cmd.Printf("the triggers of pipeline %s/%s updated\n", o.namespace, o.pipeline)
+	}
+	return
+}
+
+func (o *pipelineTriggerOption) runGetE(cmd *cobra.Command, _ []string) (err error) {
+	var pip *unstructured.Unstructured
+	if pip, err = o.getPipeline(); err != nil {
+		return
+	}
+
+	cron, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "timer_trigger", "cron")
+	token, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "remote_trigger", "token")
+	if token != "" && !o.showToken {
+		token = maskedValue
+	}
+	rows := [][]string{{"cron", common.EmptyAsNone(cron)}, {"remote-token", common.EmptyAsNone(token)}}
+	if pType, _, _ := unstructured.NestedString(pip.Object, "spec", "type"); pType == option.MultiBranchPipelineType {
+		interval, _, _ := unstructured.NestedString(pip.Object, "spec", "multi_branch_pipeline", "timer_trigger", "interval")
+		rows = [][]string{{"scan-interval", common.EmptyAsNone(formatScanInterval(interval))}}
+	}
+	err = common.PrintTable(cmd.OutOrStdout(), []string{"TRIGGER", "VALUE"}, rows)
+	return
+}
+
+func (o *pipelineTriggerOption) runClearE(cmd *cobra.Command, _ []string) (err error) {
+	var pip *unstructured.Unstructured
+	if pip, err = o.getPipeline(); err != nil {
+		return
+	}
+
+	unstructured.RemoveNestedField(pip.Object, "spec", "pipeline", "timer_trigger")
+	unstructured.RemoveNestedField(pip.Object, "spec", "pipeline", "remote_trigger")
+	unstructured.RemoveNestedField(pip.Object, "spec", "multi_branch_pipeline", "timer_trigger")
+	if err = o.updatePipeline(pip); err == nil {
+		cmd.Printf("the triggers of pipeline %s/%s cleared\n", o.namespace, o.pipeline)
+	}
+	return
+}
+
+func (o *pipelineTriggerOption) getPipeline() (pip *unstructured.Unstructured, err error) {
+	if pip, err = o.client.Resource(types.GetPipelineSchema()).Namespace(o.namespace).Get(context.TODO(), o.pipeline,
+		metav1.GetOptions{}); err != nil {
+		err = fmt.Errorf("cannot get pipeline %s/%s, error: %v", o.namespace, o.pipeline, err)
+	}
+	return
+}
+
+func (o *pipelineTriggerOption) updatePipeline(pip *unstructured.Unstructured) (err error) {
+	if _, err = o.client.Resource(types.GetPipelineSchema()).Namespace(o.namespace).Update(context.TODO(), pip,
+		metav1.UpdateOptions{}); err != nil {
+		err = fmt.Errorf("failed to update pipeline %s/%s, error: %v", o.namespace, o.pipeline, err)
+	}
+	return
+}
+
+// formatScanInterval converts the interval in milliseconds to a duration, such as: 1h0m0s
+func formatScanInterval(interval string) string {
+	if millis, err := strconv.ParseInt(interval, 10, 64); err == nil {
+		return (time.Duration(millis) * time.Millisecond).String()
+	}
+	return interval
+}
@@ -0,0 +1,143 @@
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField is a field of the Jenkins cron syntax, the bounds are inclusive
+type cronField struct {
+	name  string
+	begin int
+	end   int
+}
+
+var cronFields = []cronField{
+	{name: "minute", begin: 0, end: 59},
+	{name: "hour", begin: 0, end: 23},
+	{name: "day of month", begin: 1, end: 31},
+	{name: "month", begin: 1, end: 12},
+	{name: "day of week", begin: 0, end: 7},
+}
+
+var cronAliases = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// validateJenkinsCron checks the cron of a Jenkins timer trigger, see also:
+// https://www.jenkins.io/doc/book/pipeline/syntax/#cron-syntax
+// Each line is a cron expression, the empty lines and the comments which start with # are ignored.
+// The timezone could be set by a line like: TZ=Asia/Shanghai
+func validateJenkinsCron(spec string) (err error) {
+	var count int
+	for i, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "TZ=") {
+			if _, tzErr := time.LoadLocation(strings.TrimPrefix(line, "TZ=")); tzErr != nil {
+				err = fmt.Errorf("line %d: invalid timezone, error: %v", i+1, tzErr)
+				return
+			}
+			continue
+		}
+		if err = validateCronLine(line); err != nil {
+			err = fmt.Errorf("line %d: %v", i+1, err)
+			return
+		}
+		count++
+	}
+
+	if count == 0 {
+		err = fmt.Errorf("no cron expression found in '%s'", spec)
+	}
+	return
+}
+
+func validateCronLine(line string) (err error) {
+	if strings.HasPrefix(line, "@") {
+		for _, alias := range cronAliases {
+			if line == alias {
+				return
+			}
+		}
+		err = fmt.Errorf("unknown alias %s, supported aliases: %s", line, strings.Join(cronAliases, ", "))
+		return
+	}
+
+	items := strings.Fields(line)
+	if len(items) != len(cronFields) {
+		err = fmt.Errorf("expected %d fields but got %d in '%s'", len(cronFields), len(items), line)
+		return
+	}
+	for i, item := range items {
+		for _, term := range strings.Split(item, ",") {
+			if err = cronFields[i].validate(term); err != nil {
+				err = fmt.Errorf("invalid %s '%s': %v", cronFields[i].name, item, err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// validate checks a term of a field, such as: *, 5, 1-5, */15, H, H/15, H(0-29), H(0-29)/10, 1-30/10
+func (f cronField) validate(term string) (err error) {
+	expr, step := term, ""
+	if index := strings.Index(term, "/"); index >= 0 {
+		expr, step = term[:index], term[index+1:]
+		var stepVal int
+		if stepVal, err = strconv.Atoi(step); err != nil || stepVal <= 0 {
+			err = fmt.Errorf("the step should be a positive number")
+			return
+		}
+		if stepVal > f.end-f.begin+1 {
+			err = fmt.Errorf("the step %d is out of range %d-%d", stepVal, f.begin, f.end)
+			return
+		}
+	}
+
+	switch {
+	case expr == "*" || expr == "H":
+	case strings.HasPrefix(expr, "H(") && strings.HasSuffix(expr, ")"):
+		err = f.validateRange(strings.TrimSuffix(strings.TrimPrefix(expr, "H("), ")"), true)
+	case expr == "":
+		err = fmt.Errorf("the value is empty")
+	default:
+		if step != "" && !strings.Contains(expr, "-") {
+			err = fmt.Errorf("the step is only allowed after *, H or a range")
+			return
+		}
+		err = f.validateRange(expr, false)
+	}
+	return
+}
+
+// validateRange checks a value or a range like: 1-5, the range is required when it's the one of H(begin-end)
+func (f cronField) validateRange(expr string, rangeOnly bool) (err error) {
+	values := strings.SplitN(expr, "-", 2)
+	if rangeOnly && len(values) != 2 {
+		err = fmt.Errorf("expected a range like H(%d-%d)", f.begin, f.end)
+		return
+	}
+
+	var nums []int
+	for _, value := range values {
+		var num int
+		if num, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("'%s' is not a number", value)
+			return
+		}
+		if num < f.begin || num > f.end {
+			err = fmt.Errorf("%d is out of range %d-%d", num, f.begin, f.end)
+			return
+		}
+		nums = append(nums, num)
+	}
+	if len(nums) == 2 && nums[0] > nums[1] {
+		err = fmt.Errorf("the range %d-%d is reversed", nums[0], nums[1])
+	}
+	return
+}
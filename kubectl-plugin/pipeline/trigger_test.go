@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/pipeline/option"
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+func TestValidateJenkinsCron(t *testing.T) {
+	for _, spec := range []string{
+		"H 2 * * *",
+		"*/15 * * * *",
+		"H/15 * * * 1-5",
+		"H(0-29)/10 H(9-16) * * 1-5",
+		"0 0 1,15 * 7",
+		"1-30/10 * * * *",
+		"@daily",
+		"TZ=Asia/Shanghai\n# run at night\nH 2 * * *\n\nH 14 * * *",
+	} {
+		assert.Nil(t, validateJenkinsCron(spec), spec)
+	}
+
+	for _, spec := range []string{
+		"",
+		"# only a comment",
+		"H 2 * *",
+		"H 24 * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"5/10 * * * *",
+		"*/0 * * * *",
+		"*/61 * * * *",
+		"H(5) * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@fake",
+		"TZ=Fake/Zone\nH 2 * * *",
+	} {
+		assert.NotNil(t, validateJenkinsCron(spec), spec)
+	}
+}
+
+func TestPipelineTrigger(t *testing.T) {
+	multiBranch := newFakePipeline("ns", "multi", "")
+	_ = unstructured.SetNestedField(multiBranch.Object, option.MultiBranchPipelineType, "spec", "type")
+	client := newFakeDynamicClient(newFakePipeline("ns", "pip", "pipeline {}"), multiBranch)
+
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, executeTriggerCmd(client, buf, "set", "ns", "pip", "--cron", "H 2 * * *", "--remote-token", "my-token"))
+	assert.Contains(t, buf.String(), "the triggers of pipeline ns/pip updated")
+	pip := getFakePipeline(t, client, "pip")
+	cron, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "timer_trigger", "cron")
+	assert.Equal(t, "H 2 * * *", cron)
+	token, _, _ := unstructured.NestedString(pip.Object, "spec", "pipeline", "remote_trigger", "token")
+	assert.Equal(t, "my-token", token)
+
+	buf.Reset()
+	assert.Nil(t, executeTriggerCmd(client, buf, "get", "ns", "pip"))
+	assert.Contains(t, buf.String(), "H 2 * * *")
+	assert.Contains(t, buf.String(), maskedValue)
+	assert.NotContains(t, buf.String(), "my-token")
+
+	buf.Reset()
+	assert.Nil(t, executeTriggerCmd(client, buf, "get", "ns", "pip", "--show-token"))
+	assert.Contains(t, buf.String(), "my-token")
+
+	buf.Reset()
+	assert.Nil(t, executeTriggerCmd(client, buf, "clear", "ns", "pip"))
+	pip = getFakePipeline(t, client, "pip")
+	_, found, _ := unstructured.NestedMap(pip.Object, "spec", "pipeline", "timer_trigger")
+	assert.False(t, found)
+	_, found, _ = unstructured.NestedMap(pip.Object, "spec", "pipeline", "remote_trigger")
+	assert.False(t, found)
+
+	assert.Nil(t, executeTriggerCmd(client, buf, "set", "ns", "multi", "--scan-interval", "1h"))
+	interval, _, _ := unstructured.NestedString(getFakePipeline(t, client, "multi").Object,
+		"spec", "multi_branch_pipeline", "timer_trigger", "interval")
+	assert.Equal(t, "3600000", interval)
+	buf.Reset()
+	assert.Nil(t, executeTriggerCmd(client, buf, "get", "ns", "multi"))
+	assert.Contains(t, buf.String(), "1h0m0s")
+
+	// invalid triggers
+	assert.NotNil(t, executeTriggerCmd(client, buf, "set", "ns", "pip"))
+	assert.NotNil(t, executeTriggerCmd(client, buf, "set", "ns", "pip", "--cron", "H 25 * * *"))
+	assert.NotNil(t, executeTriggerCmd(client, buf, "set", "ns", "pip", "--scan-interval", "1h"))
+	assert.NotNil(t, executeTriggerCmd(client, buf, "set", "ns", "multi", "--cron", "H 2 * * *"))
+	assert.NotNil(t, executeTriggerCmd(client, buf, "set", "ns", "multi", "--scan-interval", "10s"))
+	assert.NotNil(t, executeTriggerCmd(client, buf, "get", "ns", "fake"))
+}
+
+func executeTriggerCmd(client dynamic.Interface, buf *bytes.Buffer, args ...string) error {
+	cmd := newPipelineTriggerCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetOut(buf)
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
+
+func getFakePipeline(t *testing.T, client dynamic.Interface, name string) *unstructured.Unstructured {
+	pip, err := client.Resource(types.GetPipelineSchema()).Namespace("ns").Get(context.TODO(), name, metav1.GetOptions{})
+	assert.Nil(t, err)
+	return pip
+}
@@ -1,52 +1,78 @@
 package pipeline
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+
+	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/types"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
-	"sigs.k8s.io/yaml"
 )
 
 // newPipelineViewCmd returns a command to view pipeline
 func newPipelineViewCmd(client dynamic.Interface) (cmd *cobra.Command) {
+	opt := &pipelineViewOption{
+		client: client,
+	}
 	cmd = &cobra.Command{
 		Use:   "view",
 		Short: "Output the YAML format of a Pipeline",
-		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			var pips []string
-			var ns string
-			if ns, pips, err = getPipelinesWithConfirm(client, args); err == nil {
-				for _, pip := range pips {
-					var rawPip *unstructured.Unstructured
-					var data []byte
-					buf := bytes.NewBuffer(data)
-					if rawPip, err = getPipeline(pip, ns, client); err == nil {
-						enc := json.NewEncoder(buf)
-						enc.SetIndent("", "    ")
-						if err = enc.Encode(rawPip); err != nil {
-							return
-						}
-					} else {
-						err = fmt.Errorf("cannot get pipeline, error: %#v", err)
-						return
-					}
-
-					var yamlData []byte
-					if yamlData, err = yaml.JSONToYAML(buf.Bytes()); err != nil {
-						return
-					}
-
-					cmd.Println(string(yamlData))
-				}
-			}
+		Long: `Output the YAML format of a Pipeline
+The Pipelines are selected interactively unless the names are given.`,
+		Example: `ks pip view devops-ns my-pipeline
+ks pip view devops-ns my-pipeline -o jsonpath='{.spec.pipeline.jenkinsfile}'`,
+		RunE: opt.runE,
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opt.output, "output", "o", outputFormatYAML,
+		"The output format, supported formats: yaml, json, name, jsonpath=<template>")
+	_ = cmd.RegisterFlagCompletionFunc("output", common.ArrayCompletion(outputFormatYAML, outputFormatJSON,
+		outputFormatName, outputFormatJSONPath))
+	return
+}
+
+type pipelineViewOption struct {
+	output string
+
+	// inner fields
+	client dynamic.Interface
+}
+
+func (o *pipelineViewOption) runE(cmd *cobra.Command, args []string) (err error) {
+	if o.client == nil {
+		o.client = common.GetDynamicClient(cmd.Root().Context())
+	}
+	if !isObjectOutputFormat(o.output) {
+		err = fmt.Errorf("not supported output format: %s", o.output)
+		return
+	}
+
+	var pips []string
+	var ns string
+	if len(args) >= 2 {
+		ns, pips = args[0], args[1:]
+	} else if ns, pips, err = getPipelinesWithConfirm(o.client, args); err != nil {
+		return
+	}
+
+	items := make([]unstructured.Unstructured, 0, len(pips))
+	for _, pip := range pips {
+		var rawPip *unstructured.Unstructured
+		if rawPip, err = getPipeline(pip, ns, o.client); err != nil {
+			err = fmt.Errorf("cannot get pipeline %s/%s, error: %v", ns, pip, err)
 			return
-		},
+		}
+		items = append(items, *rawPip)
+	}
+
+	if len(items) == 1 {
+		err = printObject(cmd.OutOrStdout(), o.output, &items[0])
+	} else if len(items) > 1 {
+		err = printObjects(cmd.OutOrStdout(), o.output, items)
 	}
 	return
 }
@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineView(t *testing.T) {
+	client := newFakeDynamicClient(newFakePipeline("ns", "pip-a", "echo"), newFakePipeline("ns", "pip-b", ""))
+
+	buf := bytes.NewBuffer(nil)
+	cmd := newPipelineViewCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "pip-a"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "kind: Pipeline")
+	assert.Contains(t, buf.String(), "name: pip-a")
+
+	buf.Reset()
+	cmd = newPipelineViewCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "pip-a", "-o", "jsonpath={.spec.pipeline.jenkinsfile}"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "echo", buf.String())
+
+	buf.Reset()
+	cmd = newPipelineViewCmd(client)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"ns", "pip-a", "pip-b", "-o", "name"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "pipeline.devops.kubesphere.io/pip-a\npipeline.devops.kubesphere.io/pip-b\n", buf.String())
+
+	cmd = newPipelineViewCmd(client)
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	cmd.SetArgs([]string{"ns", "fake"})
+	assert.NotNil(t, cmd.Execute())
+}
@@ -150,3 +150,12 @@ func GetApplicationSchema() schema.GroupVersionResource {
 		Resource: "applications",
 	}
 }
+
+// GetRoleBindingSchema returns the schema of RoleBinding
+func GetRoleBindingSchema() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "rbac.authorization.k8s.io",
+		Version:  "v1",
+		Resource: "rolebindings",
+	}
+}
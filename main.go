@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/common"
 	"github.com/kubesphere-sigs/ks/kubectl-plugin/entrypoint"
@@ -52,8 +53,31 @@ func main() {
 		cmd.PersistentFlags().AddFlagSet(kubectlPluginCmdRoot.PersistentFlags())
 		cmd.PersistentPreRunE = kubectlPluginCmdRoot.PersistentPreRunE
 		cmd.AddCommand(kubectlPluginCmds...)
+		exitWithCode(cmd)
 	}
 
 	aliasCmd.ExecuteContext(cmd, context.WithValue(context.TODO(), common.ClientFactory{}, &common.ClientFactory{}),
 		targetCommand, getDefault(), nil)
 }
+
+// exitWithCode makes the commands exit with the code which is carried by the error, such as: ks pip run --wait.
+// The other errors are handled by the alias command which exits with 1.
+func exitWithCode(cmd *cobra.Command) {
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(c *cobra.Command, args []string) (err error) {
+			if err = runE(c, args); err != nil {
+				var exitErr *common.ExitCodeError
+				if errors.As(err, &exitErr) {
+					if exitErr.Message != "" {
+						c.PrintErrln(exitErr.Message)
+					}
+					os.Exit(exitErr.Code)
+				}
+			}
+			return
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		exitWithCode(sub)
+	}
+}